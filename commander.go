@@ -1,7 +1,19 @@
 package gobot
 
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCommandTimeout is the result of a command which did not return within
+// its command timeout.
+var ErrCommandTimeout = errors.New("command timed out")
+
 type commander struct {
 	commands map[string]func(map[string]interface{}) interface{}
+	timeout  time.Duration
+	mutex    sync.RWMutex
 }
 
 // Commander is the interface which describes the behaviour for a Driver or Adaptor
@@ -15,6 +27,14 @@ type Commander interface {
 	AddCommand(name string, command func(map[string]interface{}) interface{})
 }
 
+// TimeoutCommander is implemented by the Commander returned by NewCommander,
+// and by any Commander which supports command timeouts.
+type TimeoutCommander interface {
+	// SetCommandTimeout sets the timeout applied to the commands returned by
+	// Command. A timeout of 0 disables it.
+	SetCommandTimeout(timeout time.Duration)
+}
+
 // NewCommander returns a new Commander.
 func NewCommander() Commander {
 	return &commander{
@@ -22,9 +42,17 @@ func NewCommander() Commander {
 	}
 }
 
-// Command returns the command interface whene passed a valid command name
+// Command returns the command interface whene passed a valid command name.
+// If a command timeout is set, the command returns ErrCommandTimeout when it
+// does not finish in time.
 func (c *commander) Command(name string) (command func(map[string]interface{}) interface{}) {
 	command, _ = c.commands[name]
+	c.mutex.RLock()
+	timeout := c.timeout
+	c.mutex.RUnlock()
+	if command != nil && timeout > 0 {
+		command = TimeoutCommand(timeout, command)
+	}
 	return
 }
 
@@ -37,3 +65,30 @@ func (c *commander) Commands() map[string]func(map[string]interface{}) interface
 func (c *commander) AddCommand(name string, command func(map[string]interface{}) interface{}) {
 	c.commands[name] = command
 }
+
+// SetCommandTimeout sets the timeout applied to the commands returned by Command.
+func (c *commander) SetCommandTimeout(timeout time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.timeout = timeout
+}
+
+// TimeoutCommand returns a command which runs command and returns its result,
+// or ErrCommandTimeout if it does not return within timeout. Commands cannot
+// be cancelled: a timed out command keeps running in its own goroutine until
+// it returns, so a command which never returns leaks that goroutine.
+func TimeoutCommand(timeout time.Duration, command func(map[string]interface{}) interface{}) func(map[string]interface{}) interface{} {
+	return func(params map[string]interface{}) interface{} {
+		result := make(chan interface{}, 1)
+		go func() {
+			result <- command(params)
+		}()
+
+		select {
+		case r := <-result:
+			return r
+		case <-time.After(timeout):
+			return ErrCommandTimeout
+		}
+	}
+}
@@ -2,6 +2,7 @@ package gobot
 
 import (
 	"testing"
+	"time"
 
 	"gobot.io/x/gobot/gobottest"
 )
@@ -22,3 +23,37 @@ func TestCommaner(t *testing.T) {
 	command = c.Command("booyeah")
 	gobottest.Assert(t, command, (func(map[string]interface{}) interface{})(nil))
 }
+
+func TestCommanderTimeout(t *testing.T) {
+	c := NewCommander()
+	c.AddCommand("fast", func(map[string]interface{}) interface{} {
+		return "hi"
+	})
+	c.AddCommand("slow", func(map[string]interface{}) interface{} {
+		<-time.After(100 * time.Millisecond)
+		return "hi"
+	})
+
+	gobottest.Assert(t, c.Command("slow")(nil), "hi")
+
+	c.(TimeoutCommander).SetCommandTimeout(10 * time.Millisecond)
+	gobottest.Assert(t, c.Command("fast")(nil), "hi")
+	gobottest.Assert(t, c.Command("slow")(nil), ErrCommandTimeout)
+	gobottest.Assert(t, c.Command("booyeah"), (func(map[string]interface{}) interface{})(nil))
+
+	c.(TimeoutCommander).SetCommandTimeout(0)
+	gobottest.Assert(t, c.Command("slow")(nil), "hi")
+}
+
+func TestTimeoutCommand(t *testing.T) {
+	slow := TimeoutCommand(10*time.Millisecond, func(map[string]interface{}) interface{} {
+		<-time.After(100 * time.Millisecond)
+		return "hi"
+	})
+	gobottest.Assert(t, slow(nil), ErrCommandTimeout)
+
+	fast := TimeoutCommand(100*time.Millisecond, func(params map[string]interface{}) interface{} {
+		return params["a"]
+	})
+	gobottest.Assert(t, fast(map[string]interface{}{"a": 1}), 1)
+}
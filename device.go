@@ -1,6 +1,7 @@
 package gobot
 
 import (
+	"context"
 	"log"
 	"reflect"
 
@@ -54,6 +55,11 @@ func (d *Devices) Each(f func(Device)) {
 
 // Start calls Start on each Device in d
 func (d *Devices) Start() (err error) {
+	return d.StartContext(context.Background())
+}
+
+// StartContext starts each Device in d with ctx, see StartContext
+func (d *Devices) StartContext(ctx context.Context) (err error) {
 	log.Println("Starting devices...")
 	for _, device := range *d {
 		info := "Starting device " + device.Name()
@@ -63,7 +69,7 @@ func (d *Devices) Start() (err error) {
 		}
 
 		log.Println(info + "...")
-		if derr := device.Start(); derr != nil {
+		if derr := StartContext(ctx, device); derr != nil {
 			err = multierror.Append(err, derr)
 		}
 	}
@@ -1,5 +1,7 @@
 package gobot
 
+import "context"
+
 // Driver is the interface that describes a driver in gobot
 type Driver interface {
 	// Name returns the label for the Driver
@@ -18,3 +20,18 @@ type Driver interface {
 type Pinner interface {
 	Pin() string
 }
+
+// ContextStarter is the interface of Drivers which can be started with a
+// context, and halt on their own once the context is done
+type ContextStarter interface {
+	StartContext(ctx context.Context) error
+}
+
+// StartContext starts the Driver with ctx when it is a ContextStarter, or
+// calls its Start otherwise.
+func StartContext(ctx context.Context, d Driver) error {
+	if starter, ok := d.(ContextStarter); ok {
+		return starter.StartContext(ctx)
+	}
+	return d.Start()
+}
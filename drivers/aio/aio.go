@@ -17,6 +17,10 @@ const (
 	Data = "data"
 	// Vibration event
 	Vibration = "vibration"
+	// UpperLimit event
+	UpperLimit = "upperLimit"
+	// LowerLimit event
+	LowerLimit = "lowerLimit"
 )
 
 // AnalogReader interface represents an Adaptor which has Analog capabilities
@@ -1,6 +1,7 @@
 package aio
 
 import (
+	"sync"
 	"time"
 
 	"gobot.io/x/gobot"
@@ -13,6 +14,14 @@ type AnalogSensorDriver struct {
 	halt       chan bool
 	interval   time.Duration
 	connection AnalogReader
+	mtx        sync.Mutex
+
+	limits     bool
+	lower      int
+	upper      int
+	hysteresis int
+	aboveUpper bool
+	belowLower bool
 	gobot.Eventer
 	gobot.Commander
 }
@@ -42,6 +51,8 @@ func NewAnalogSensorDriver(a AnalogReader, pin string, v ...time.Duration) *Anal
 
 	d.AddEvent(Data)
 	d.AddEvent(Error)
+	d.AddEvent(UpperLimit)
+	d.AddEvent(LowerLimit)
 
 	d.AddCommand("Read", func(params map[string]interface{}) interface{} {
 		val, err := d.Read()
@@ -55,21 +66,26 @@ func NewAnalogSensorDriver(a AnalogReader, pin string, v ...time.Duration) *Anal
 // Emits the Events:
 //	Data int - Event is emitted on change and represents the current reading from the sensor.
 //	Error error - Event is emitted on error reading from the sensor.
+//	UpperLimit int - Event is emitted when the reading reaches the upper limit, see SetLimits.
+//	LowerLimit int - Event is emitted when the reading reaches the lower limit, see SetLimits.
 func (a *AnalogSensorDriver) Start() (err error) {
 	var value int = 0
 	go func() {
-		timer := time.NewTimer(a.interval)
+		timer := time.NewTimer(a.Interval())
 		timer.Stop()
 		for {
 			newValue, err := a.Read()
 			if err != nil {
 				a.Publish(a.Event(Error), err)
-			} else if newValue != value && newValue != -1 {
-				value = newValue
-				a.Publish(a.Event(Data), value)
+			} else if newValue != -1 {
+				if newValue != value {
+					value = newValue
+					a.Publish(a.Event(Data), value)
+				}
+				a.checkLimits(newValue)
 			}
 
-			timer.Reset(a.interval)
+			timer.Reset(a.Interval())
 			select {
 			case <-timer.C:
 			case <-a.halt:
@@ -99,6 +115,64 @@ func (a *AnalogSensorDriver) Pin() string { return a.pin }
 // Connection returns the AnalogSensorDrivers Connection
 func (a *AnalogSensorDriver) Connection() gobot.Connection { return a.connection.(gobot.Connection) }
 
+// Interval returns the interval at which the sensor is polled
+func (a *AnalogSensorDriver) Interval() time.Duration {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return a.interval
+}
+
+// SetInterval sets the interval at which the sensor is polled, it applies
+// from the next read
+func (a *AnalogSensorDriver) SetInterval(interval time.Duration) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.interval = interval
+}
+
+// SetLimits enables the UpperLimit and LowerLimit events, emitted when a
+// reading reaches upper or goes down to lower. Once emitted, an event is
+// emitted again only after the reading went back past the limit by more than
+// hysteresis, so that a reading hovering at the limit does not fire it
+// repeatedly.
+func (a *AnalogSensorDriver) SetLimits(lower, upper, hysteresis int) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.limits = true
+	a.lower, a.upper, a.hysteresis = lower, upper, hysteresis
+	a.aboveUpper, a.belowLower = false, false
+}
+
+func (a *AnalogSensorDriver) checkLimits(value int) {
+	a.mtx.Lock()
+	if !a.limits {
+		a.mtx.Unlock()
+		return
+	}
+
+	upper := !a.aboveUpper && value >= a.upper
+	if upper {
+		a.aboveUpper = true
+	} else if a.aboveUpper && value < a.upper-a.hysteresis {
+		a.aboveUpper = false
+	}
+
+	lower := !a.belowLower && value <= a.lower
+	if lower {
+		a.belowLower = true
+	} else if a.belowLower && value > a.lower+a.hysteresis {
+		a.belowLower = false
+	}
+	a.mtx.Unlock()
+
+	if upper {
+		a.Publish(a.Event(UpperLimit), value)
+	}
+	if lower {
+		a.Publish(a.Event(LowerLimit), value)
+	}
+}
+
 // Read returns the current reading from the Analog Sensor
 func (a *AnalogSensorDriver) Read() (val int, err error) {
 	return a.connection.AnalogRead(a.Pin())
@@ -3,6 +3,7 @@ package aio
 import (
 	"errors"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -97,6 +98,42 @@ func TestAnalogSensorDriverStart(t *testing.T) {
 	}
 }
 
+func TestAnalogSensorDriverLimits(t *testing.T) {
+	d := NewAnalogSensorDriver(newAioTestAdaptor(), "1")
+	events := map[string][]int{}
+	var mtx sync.Mutex
+	for _, name := range []string{UpperLimit, LowerLimit} {
+		name := name
+		d.On(d.Event(name), func(data interface{}) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			events[name] = append(events[name], data.(int))
+		})
+	}
+
+	// no limits set
+	d.checkLimits(1000)
+
+	d.SetLimits(100, 900, 10)
+	for _, value := range []int{500, 900, 895, 905, 889, 901, 100, 90, 105, 111, 99} {
+		d.checkLimits(value)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	mtx.Lock()
+	defer mtx.Unlock()
+	gobottest.Assert(t, events, map[string][]int{
+		UpperLimit: {900, 901},
+		LowerLimit: {100, 99},
+	})
+}
+
+func TestAnalogSensorDriverSetInterval(t *testing.T) {
+	d := NewAnalogSensorDriver(newAioTestAdaptor(), "1")
+	d.SetInterval(time.Second)
+	gobottest.Assert(t, d.Interval(), time.Second)
+}
+
 func TestAnalogSensorDriverHalt(t *testing.T) {
 	d := NewAnalogSensorDriver(newAioTestAdaptor(), "1")
 	done := make(chan struct{})
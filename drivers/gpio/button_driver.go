@@ -10,11 +10,18 @@ import (
 type ButtonDriver struct {
 	Active       bool
 	DefaultState int
-	pin          string
-	name         string
-	halt         chan bool
-	interval     time.Duration
-	connection   DigitalReader
+
+	// DebounceCount is the number of consecutive reads a new value has to be
+	// seen for before the button state changes. Defaults to 2, so that a
+	// single noisy read does not fire an event. Set it to 1 to change the
+	// state on the first read of a new value.
+	DebounceCount int
+
+	pin        string
+	name       string
+	halt       chan bool
+	interval   time.Duration
+	connection DigitalReader
 	gobot.Eventer
 }
 
@@ -25,14 +32,15 @@ type ButtonDriver struct {
 //  time.Duration: Interval at which the ButtonDriver is polled for new information
 func NewButtonDriver(a DigitalReader, pin string, v ...time.Duration) *ButtonDriver {
 	b := &ButtonDriver{
-		name:         gobot.DefaultName("Button"),
-		connection:   a,
-		pin:          pin,
-		Active:       false,
-		DefaultState: 0,
-		Eventer:      gobot.NewEventer(),
-		interval:     10 * time.Millisecond,
-		halt:         make(chan bool),
+		name:          gobot.DefaultName("Button"),
+		connection:    a,
+		pin:           pin,
+		Active:        false,
+		DefaultState:  0,
+		DebounceCount: 2,
+		Eventer:       gobot.NewEventer(),
+		interval:      10 * time.Millisecond,
+		halt:          make(chan bool),
 	}
 
 	if len(v) > 0 {
@@ -55,13 +63,20 @@ func NewButtonDriver(a DigitalReader, pin string, v ...time.Duration) *ButtonDri
 func (b *ButtonDriver) Start() (err error) {
 	state := b.DefaultState
 	go func() {
+		seen := 0
 		for {
 			newValue, err := b.connection.DigitalRead(b.Pin())
 			if err != nil {
 				b.Publish(Error, err)
 			} else if newValue != state && newValue != -1 {
-				state = newValue
-				b.update(newValue)
+				seen++
+				if seen >= b.DebounceCount {
+					seen = 0
+					state = newValue
+					b.update(newValue)
+				}
+			} else {
+				seen = 0
 			}
 			select {
 			case <-time.After(b.interval):
@@ -147,6 +147,81 @@ func TestButtonDriverDefaultState(t *testing.T) {
 	}
 }
 
+func TestButtonDriverDebounce(t *testing.T) {
+	sem := make(chan bool, 1)
+	a := newGpioTestAdaptor()
+	d := NewButtonDriver(a, "1", time.Millisecond)
+	d.DebounceCount = 3
+
+	d.On(ButtonPush, func(data interface{}) {
+		sem <- true
+	})
+
+	// never the same value three times in a row
+	reads := 0
+	a.TestAdaptorDigitalRead(func() (val int, err error) {
+		reads++
+		if reads%3 == 0 {
+			val = 0
+		} else {
+			val = 1
+		}
+		return
+	})
+
+	gobottest.Assert(t, d.Start(), nil)
+
+	select {
+	case <-sem:
+		t.Errorf("Button Event \"Push\" should not be published for a noisy read")
+	case <-time.After(buttonTestDelay * time.Millisecond):
+	}
+
+	a.TestAdaptorDigitalRead(func() (val int, err error) {
+		val = 1
+		return
+	})
+
+	select {
+	case <-sem:
+	case <-time.After(buttonTestDelay * time.Millisecond):
+		t.Errorf("Button Event \"Push\" was not published")
+	}
+
+	d.Halt()
+}
+
+func TestButtonDriverDebounceGlitch(t *testing.T) {
+	sem := make(chan bool, 1)
+	a := newGpioTestAdaptor()
+	d := NewButtonDriver(a, "1", time.Millisecond)
+	gobottest.Assert(t, d.DebounceCount, 2)
+
+	d.On(ButtonPush, func(data interface{}) {
+		sem <- true
+	})
+
+	// a single read of 1 every ten reads
+	reads := 0
+	a.TestAdaptorDigitalRead(func() (val int, err error) {
+		reads++
+		if reads%10 == 0 {
+			val = 1
+		}
+		return
+	})
+
+	gobottest.Assert(t, d.Start(), nil)
+
+	select {
+	case <-sem:
+		t.Errorf("Button Event \"Push\" should not be published for a single noisy read")
+	case <-time.After(buttonTestDelay * time.Millisecond):
+	}
+
+	d.Halt()
+}
+
 func TestButtonDriverDefaultName(t *testing.T) {
 	g := initTestButtonDriver()
 	gobottest.Assert(t, strings.HasPrefix(g.Name(), "Button"), true)
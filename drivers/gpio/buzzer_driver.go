@@ -1,6 +1,7 @@
 package gpio
 
 import (
+	"sync"
 	"time"
 
 	"gobot.io/x/gobot"
@@ -202,13 +203,102 @@ func (l *BuzzerDriver) Toggle() (err error) {
 	return
 }
 
+// Note is a tone of Frequency hz, or a rest when Frequency is Rest, played
+// for Duration by BuzzerDriver.Play
+type Note struct {
+	Frequency float64
+	Duration  time.Duration
+}
+
+// Tone plays a tone of hz for the duration, in beats at the BPM of the
+// buzzer, and returns once it is over. When the connection is a ToneWriter,
+// such as a Firmata board with the tone feature, the board plays the tone;
+// otherwise the pin is toggled at the frequency of the tone.
 func (l *BuzzerDriver) Tone(hz, duration float64) (err error) {
 	// calculation based off https://www.arduino.cc/en/Tutorial/Melody
+	tempo := ((60 / l.BPM) * (duration * 1000))
+
+	return l.play(hz, time.Duration(tempo*float64(time.Millisecond)), nil)
+}
+
+// Play plays the notes in sequence and returns once the last one is over.
+func (l *BuzzerDriver) Play(notes []Note) (err error) {
+	for _, note := range notes {
+		if err = l.play(note.Frequency, note.Duration, nil); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// PlayAsync plays the notes in sequence in the background, so that a jingle
+// does not hold up the work of the robot. Calling the returned stop func
+// interrupts the notes left, waits for the buzzer to be silent and returns
+// the error met while playing, if any. It is safe to call stop more than
+// once and after the notes are over.
+func (l *BuzzerDriver) PlayAsync(notes []Note) (stop func() error) {
+	halt := make(chan struct{})
+	done := make(chan struct{})
+	var err error
+	var once sync.Once
+
+	go func() {
+		defer close(done)
+		for _, note := range notes {
+			select {
+			case <-halt:
+				return
+			default:
+			}
+			if err = l.play(note.Frequency, note.Duration, halt); err != nil {
+				return
+			}
+		}
+	}()
+
+	return func() error {
+		once.Do(func() { close(halt) })
+		<-done
+		return err
+	}
+}
+
+// play plays a tone of hz for d, or until halt is closed.
+func (l *BuzzerDriver) play(hz float64, d time.Duration, halt <-chan struct{}) (err error) {
+	if writer, ok := l.connection.(ToneWriter); ok {
+		if hz > 0 {
+			err = writer.Tone(l.Pin(), int(hz), int(d/time.Millisecond))
+		} else {
+			err = writer.NoTone(l.Pin())
+		}
+		if err != nil {
+			return
+		}
+		select {
+		case <-time.After(d):
+		case <-halt:
+			return writer.NoTone(l.Pin())
+		}
+		return
+	}
+
+	if hz <= 0 {
+		select {
+		case <-time.After(d):
+		case <-halt:
+		}
+		return
+	}
+
 	tone := (1.0 / (2.0 * hz)) * 1000000.0
 
-	tempo := ((60 / l.BPM) * (duration * 1000))
+	for i := 0.0; i < float64(d/time.Microsecond); i += tone * 2.0 {
+		select {
+		case <-halt:
+			return
+		default:
+		}
 
-	for i := 0.0; i < tempo*1000; i += tone * 2.0 {
 		if err = l.On(); err != nil {
 			return
 		}
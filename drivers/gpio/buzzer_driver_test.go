@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"gobot.io/x/gobot"
 	"gobot.io/x/gobot/gobottest"
@@ -50,6 +51,70 @@ func TestBuzzerDriverTone(t *testing.T) {
 	gobottest.Assert(t, d.Tone(100, 0.01), nil)
 }
 
+type gpioTestToneWriter struct {
+	gpioTestDigitalWriter
+	tones [][2]int
+}
+
+func (t *gpioTestToneWriter) Tone(pin string, frequency int, durationMs int) (err error) {
+	t.tones = append(t.tones, [2]int{frequency, durationMs})
+	return
+}
+
+func (t *gpioTestToneWriter) NoTone(pin string) (err error) {
+	t.tones = append(t.tones, [2]int{0, 0})
+	return
+}
+
+func TestBuzzerDriverToneWriter(t *testing.T) {
+	a := &gpioTestToneWriter{}
+	d := initTestBuzzerDriver(a)
+	d.BPM = 6000
+	gobottest.Assert(t, d.Tone(C4, Quarter), nil)
+	gobottest.Assert(t, d.Tone(Rest, Quarter), nil)
+	gobottest.Assert(t, a.tones, [][2]int{{261, 10}, {0, 0}})
+}
+
+func TestBuzzerDriverPlay(t *testing.T) {
+	a := &gpioTestToneWriter{}
+	d := initTestBuzzerDriver(a)
+	gobottest.Assert(t, d.Play([]Note{
+		{C4, 10 * time.Millisecond},
+		{Rest, 5 * time.Millisecond},
+		{A4, 20 * time.Millisecond},
+	}), nil)
+	gobottest.Assert(t, a.tones, [][2]int{{261, 10}, {0, 0}, {440, 20}})
+}
+
+func TestBuzzerDriverPlayAsync(t *testing.T) {
+	a := &gpioTestToneWriter{}
+	d := initTestBuzzerDriver(a)
+	stop := d.PlayAsync([]Note{{C4, 10 * time.Millisecond}, {E4, 10 * time.Millisecond}})
+	time.Sleep(50 * time.Millisecond)
+	gobottest.Assert(t, stop(), nil)
+	gobottest.Assert(t, a.tones, [][2]int{{261, 10}, {329, 10}})
+
+	// stopping interrupts the note being played and skips the rest
+	a.tones = nil
+	stop = d.PlayAsync([]Note{{G4, time.Second}, {C5, time.Second}})
+	time.Sleep(10 * time.Millisecond)
+	gobottest.Assert(t, stop(), nil)
+	gobottest.Assert(t, stop(), nil)
+	gobottest.Assert(t, a.tones, [][2]int{{392, 1000}, {0, 0}})
+}
+
+func TestBuzzerDriverPlayAsyncError(t *testing.T) {
+	a := newGpioTestAdaptor()
+	d := initTestBuzzerDriver(a)
+	a.TestAdaptorDigitalWrite(func() (err error) {
+		return errors.New("write error")
+	})
+
+	stop := d.PlayAsync([]Note{{C4, 10 * time.Millisecond}})
+	time.Sleep(20 * time.Millisecond)
+	gobottest.Assert(t, stop(), errors.New("write error"))
+}
+
 func TestBuzzerDriverOnError(t *testing.T) {
 	a := newGpioTestAdaptor()
 	d := initTestBuzzerDriver(a)
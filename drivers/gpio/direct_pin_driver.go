@@ -19,7 +19,7 @@ type DirectPinDriver struct {
 // Adds the following API Commands:
 // 	"DigitalRead" - See DirectPinDriver.DigitalRead
 // 	"DigitalWrite" - See DirectPinDriver.DigitalWrite
-// 	"AnalogWrite" - See DirectPinDriver.AnalogWrite
+// 	"AnalogRead" - See DirectPinDriver.AnalogRead
 // 	"PwmWrite" - See DirectPinDriver.PwmWrite
 // 	"ServoWrite" - See DirectPinDriver.ServoWrite
 func NewDirectPinDriver(a gobot.Connection, pin string) *DirectPinDriver {
@@ -34,6 +34,10 @@ func NewDirectPinDriver(a gobot.Connection, pin string) *DirectPinDriver {
 		val, err := d.DigitalRead()
 		return map[string]interface{}{"val": val, "err": err}
 	})
+	d.AddCommand("AnalogRead", func(params map[string]interface{}) interface{} {
+		val, err := d.AnalogRead()
+		return map[string]interface{}{"val": val, "err": err}
+	})
 	d.AddCommand("DigitalWrite", func(params map[string]interface{}) interface{} {
 		level, _ := strconv.Atoi(params["level"].(string))
 		return d.DigitalWrite(byte(level))
@@ -104,6 +108,15 @@ func (d *DirectPinDriver) DigitalWrite(level byte) (err error) {
 	return
 }
 
+// AnalogRead returns the current analog reading of the pin
+func (d *DirectPinDriver) AnalogRead() (val int, err error) {
+	if reader, ok := d.Connection().(AnalogReader); ok {
+		return reader.AnalogRead(d.Pin())
+	}
+	err = ErrAnalogReadUnsupported
+	return
+}
+
 // PwmWrite writes the 0-254 value to the specified pin
 func (d *DirectPinDriver) PwmWrite(level byte) (err error) {
 	if writer, ok := d.Connection().(PwmWriter); ok {
@@ -125,6 +125,28 @@ func TestDirectPinDriverDigitalReadNotSupported(t *testing.T) {
 	gobottest.Assert(t, e, errors.New("DigitalRead is not supported by this platform"))
 }
 
+func TestDirectPinDriverAnalogRead(t *testing.T) {
+	a := newGpioTestAdaptor()
+	a.TestAdaptorAnalogRead(func() (val int, err error) {
+		return 512, nil
+	})
+	d := NewDirectPinDriver(a, "1")
+	ret, err := d.AnalogRead()
+	gobottest.Assert(t, ret, 512)
+	gobottest.Assert(t, err, nil)
+
+	cmd := d.Command("AnalogRead")(nil).(map[string]interface{})
+	gobottest.Assert(t, cmd["val"].(int), 512)
+	gobottest.Assert(t, cmd["err"], nil)
+}
+
+func TestDirectPinDriverAnalogReadNotSupported(t *testing.T) {
+	a := &gpioTestBareAdaptor{}
+	d := NewDirectPinDriver(a, "1")
+	_, e := d.AnalogRead()
+	gobottest.Assert(t, e, errors.New("AnalogRead is not supported by this platform"))
+}
+
 func TestDirectPinDriverPwmWrite(t *testing.T) {
 	a := newGpioTestAdaptor()
 	d := NewDirectPinDriver(a, "1")
@@ -2,6 +2,7 @@ package gpio
 
 import (
 	"errors"
+	"time"
 )
 
 var (
@@ -20,9 +21,12 @@ var (
 	// ErrDigitalReadUnsupported is the error resulting when a driver attempts to use
 	// hardware capabilities which a connection does not support
 	ErrDigitalReadUnsupported = errors.New("DigitalRead is not supported by this platform")
-	// ErrServoOutOfRange is the error resulting when a driver attempts to use
+	// ErrPulseReadUnsupported is the error resulting when a driver attempts to use
 	// hardware capabilities which a connection does not support
-	ErrServoOutOfRange = errors.New("servo angle must be between 0-180")
+	ErrPulseReadUnsupported = errors.New("PulseRead is not supported by this platform")
+	// ErrServoOutOfRange is the error resulting when a servo is moved to an
+	// angle outside of its range
+	ErrServoOutOfRange = errors.New("servo angle is out of the range of the servo")
 )
 
 const (
@@ -52,12 +56,30 @@ type ServoWriter interface {
 	ServoWrite(string, byte) (err error)
 }
 
+// ToneWriter interface represents an Adaptor which can play tones on a pin
+type ToneWriter interface {
+	Tone(pin string, frequency int, durationMs int) (err error)
+	NoTone(pin string) (err error)
+}
+
 // DigitalWriter interface represents an Adaptor which has DigitalWrite capabilities
 type DigitalWriter interface {
 	DigitalWrite(string, byte) (err error)
 }
 
+// AnalogReader interface represents an Adaptor which has AnalogRead capabilities
+type AnalogReader interface {
+	AnalogRead(string) (val int, err error)
+}
+
 // DigitalReader interface represents an Adaptor which has DigitalRead capabilities
 type DigitalReader interface {
 	DigitalRead(string) (val int, err error)
 }
+
+// PulseReader interface represents an Adaptor which can time the pulses on a
+// pin. PulseRead waits for the pin to go to level and returns how long it
+// stays there, or 0 when the pulse does not start and end within timeout.
+type PulseReader interface {
+	PulseRead(pin string, level byte, timeout time.Duration) (d time.Duration, err error)
+}
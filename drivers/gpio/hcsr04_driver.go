@@ -0,0 +1,139 @@
+package gpio
+
+import (
+	"errors"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+// ErrHCSR04NoEcho is the error returned when the HC-SR04 does not send back
+// the echo of its trigger, as when nothing is in range
+var ErrHCSR04NoEcho = errors.New("No echo received from the HC-SR04")
+
+const (
+	// hcsr04TriggerPulse is how long the trigger pin is held high to start a
+	// measurement
+	hcsr04TriggerPulse = 10 * time.Microsecond
+	// hcsr04EchoTimeout is how long to wait for the echo pulse, which the
+	// HC-SR04 holds for 38ms when it receives no echo
+	hcsr04EchoTimeout = 40 * time.Millisecond
+	// hcsr04SpeedOfSound is the speed of sound in cm/s
+	hcsr04SpeedOfSound = 34300.0
+)
+
+// HCSR04Driver represents an HC-SR04 ultrasonic distance sensor
+type HCSR04Driver struct {
+	name       string
+	triggerPin string
+	echoPin    string
+	halt       chan bool
+	interval   time.Duration
+	connection DigitalWriter
+	gobot.Eventer
+}
+
+// NewHCSR04Driver returns a new HCSR04Driver with a polling interval of
+// 100 Milliseconds given a DigitalWriter, its trigger pin and echo pin.
+// Timing the echo pulse needs an Adaptor which implements PulseReader, which
+// none of the Adaptors included with Gobot do yet. StandardFirmata can not
+// time microsecond pulses, so the Firmata Adaptor does not.
+//
+// Optionally accepts:
+//
+//	time.Duration: Interval at which the HCSR04Driver is polled for new information
+func NewHCSR04Driver(a DigitalWriter, triggerPin string, echoPin string, v ...time.Duration) *HCSR04Driver {
+	h := &HCSR04Driver{
+		name:       gobot.DefaultName("HCSR04"),
+		connection: a,
+		triggerPin: triggerPin,
+		echoPin:    echoPin,
+		Eventer:    gobot.NewEventer(),
+		interval:   100 * time.Millisecond,
+		halt:       make(chan bool),
+	}
+
+	if len(v) > 0 {
+		h.interval = v[0]
+	}
+
+	h.AddEvent(Data)
+	h.AddEvent(Error)
+
+	return h
+}
+
+// Start starts the HCSR04Driver and measures the distance at the given interval.
+//
+// Emits the Events:
+//
+//	Data float64 - Distance measured, in centimeters
+//	Error error - On measure error, ErrHCSR04NoEcho when no echo returns
+func (h *HCSR04Driver) Start() (err error) {
+	go func() {
+		for {
+			distance, err := h.Distance()
+			if err != nil {
+				h.Publish(Error, err)
+			} else {
+				h.Publish(Data, distance)
+			}
+
+			select {
+			case <-time.After(h.interval):
+			case <-h.halt:
+				return
+			}
+		}
+	}()
+	return
+}
+
+// Halt stops polling the HCSR04Driver for new information
+func (h *HCSR04Driver) Halt() (err error) {
+	h.halt <- true
+	return
+}
+
+// Distance triggers a measurement and returns the distance to the obstacle
+// in centimeters, from the duration of the echo pulse. It returns
+// ErrHCSR04NoEcho when the echo does not return.
+func (h *HCSR04Driver) Distance() (float64, error) {
+	reader, ok := h.connection.(PulseReader)
+	if !ok {
+		return 0, ErrPulseReadUnsupported
+	}
+
+	if err := h.connection.DigitalWrite(h.TriggerPin(), 1); err != nil {
+		return 0, err
+	}
+	time.Sleep(hcsr04TriggerPulse)
+	if err := h.connection.DigitalWrite(h.TriggerPin(), 0); err != nil {
+		return 0, err
+	}
+
+	echo, err := reader.PulseRead(h.EchoPin(), 1, hcsr04EchoTimeout)
+	if err != nil {
+		return 0, err
+	}
+	if echo == 0 || echo >= 38*time.Millisecond {
+		return 0, ErrHCSR04NoEcho
+	}
+	// the pulse lasts the round trip to the obstacle
+	return echo.Seconds() * hcsr04SpeedOfSound / 2, nil
+}
+
+// Name returns the HCSR04Driver name
+func (h *HCSR04Driver) Name() string { return h.name }
+
+// SetName sets the HCSR04Driver name
+func (h *HCSR04Driver) SetName(n string) { h.name = n }
+
+// TriggerPin returns the HCSR04Driver trigger pin
+func (h *HCSR04Driver) TriggerPin() string { return h.triggerPin }
+
+// EchoPin returns the HCSR04Driver echo pin
+func (h *HCSR04Driver) EchoPin() string { return h.echoPin }
+
+// Connection returns the HCSR04Driver Connection
+func (h *HCSR04Driver) Connection() gobot.Connection { return h.connection.(gobot.Connection) }
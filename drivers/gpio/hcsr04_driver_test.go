@@ -0,0 +1,96 @@
+package gpio
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*HCSR04Driver)(nil)
+
+type gpioTestPulseReader struct {
+	*gpioTestAdaptor
+	pulseRead func(pin string, level byte, timeout time.Duration) (time.Duration, error)
+}
+
+func (t *gpioTestPulseReader) PulseRead(pin string, level byte, timeout time.Duration) (time.Duration, error) {
+	return t.pulseRead(pin, level, timeout)
+}
+
+func initTestHCSR04Driver(echo time.Duration, err error) (*HCSR04Driver, *gpioTestPulseReader) {
+	a := &gpioTestPulseReader{
+		gpioTestAdaptor: newGpioTestAdaptor(),
+		pulseRead: func(string, byte, time.Duration) (time.Duration, error) {
+			return echo, err
+		},
+	}
+	return NewHCSR04Driver(a, "7", "8"), a
+}
+
+func TestHCSR04Driver(t *testing.T) {
+	d, _ := initTestHCSR04Driver(0, nil)
+	gobottest.Refute(t, d.Connection(), nil)
+	gobottest.Assert(t, d.TriggerPin(), "7")
+	gobottest.Assert(t, d.EchoPin(), "8")
+	gobottest.Assert(t, d.interval, 100*time.Millisecond)
+
+	d = NewHCSR04Driver(newGpioTestAdaptor(), "7", "8", 30*time.Second)
+	gobottest.Assert(t, d.interval, 30*time.Second)
+}
+
+func TestHCSR04DriverDistance(t *testing.T) {
+	d, a := initTestHCSR04Driver(5830*time.Microsecond, nil)
+	distance, err := d.Distance()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, distance > 99.9 && distance < 100.1, true)
+	gobottest.Assert(t, a.Written(), []gpioTestWrite{{"DigitalWrite", "7", 1}, {"DigitalWrite", "7", 0}})
+
+	d, _ = initTestHCSR04Driver(0, nil)
+	_, err = d.Distance()
+	gobottest.Assert(t, err, ErrHCSR04NoEcho)
+
+	d, _ = initTestHCSR04Driver(38*time.Millisecond, nil)
+	_, err = d.Distance()
+	gobottest.Assert(t, err, ErrHCSR04NoEcho)
+
+	d, _ = initTestHCSR04Driver(0, errors.New("pulse read error"))
+	_, err = d.Distance()
+	gobottest.Assert(t, err, errors.New("pulse read error"))
+
+	d = NewHCSR04Driver(newGpioTestAdaptor(), "7", "8")
+	_, err = d.Distance()
+	gobottest.Assert(t, err, ErrPulseReadUnsupported)
+}
+
+func TestHCSR04DriverStart(t *testing.T) {
+	d, _ := initTestHCSR04Driver(5830*time.Microsecond, nil)
+	d.interval = time.Millisecond
+	data := make(chan interface{}, 1)
+	d.Once(Data, func(distance interface{}) { data <- distance })
+
+	gobottest.Assert(t, d.Start(), nil)
+	select {
+	case distance := <-data:
+		gobottest.Assert(t, distance.(float64) > 99.9, true)
+	case <-time.After(time.Second):
+		t.Errorf("HCSR04Driver Event \"Data\" was not published")
+	}
+	gobottest.Assert(t, d.Halt(), nil)
+
+	d, _ = initTestHCSR04Driver(0, nil)
+	d.interval = time.Millisecond
+	errs := make(chan interface{}, 1)
+	d.Once(Error, func(err interface{}) { errs <- err })
+
+	gobottest.Assert(t, d.Start(), nil)
+	select {
+	case err := <-errs:
+		gobottest.Assert(t, err, ErrHCSR04NoEcho)
+	case <-time.After(time.Second):
+		t.Errorf("HCSR04Driver Event \"Error\" was not published")
+	}
+	gobottest.Assert(t, d.Halt(), nil)
+}
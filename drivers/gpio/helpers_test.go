@@ -15,9 +15,18 @@ type gpioTestDigitalWriter struct {
 
 func (t *gpioTestDigitalWriter) DigitalWrite(string, byte) (err error) { return }
 
+// gpioTestWrite is a write recorded by gpioTestAdaptor, such as
+// {"DigitalWrite", "1", 1}
+type gpioTestWrite struct {
+	op  string
+	pin string
+	val byte
+}
+
 type gpioTestAdaptor struct {
 	name                    string
 	port                    string
+	written                 []gpioTestWrite
 	mtx                     sync.Mutex
 	testAdaptorDigitalWrite func() (err error)
 	testAdaptorServoWrite   func() (err error)
@@ -52,14 +61,37 @@ func (t *gpioTestAdaptor) TestAdaptorDigitalRead(f func() (val int, err error))
 	t.testAdaptorDigitalRead = f
 }
 
-func (t *gpioTestAdaptor) ServoWrite(string, byte) (err error) {
+// Written returns the writes recorded so far
+func (t *gpioTestAdaptor) Written() []gpioTestWrite {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return append([]gpioTestWrite{}, t.written...)
+}
+
+// WrittenValues returns the values of the op writes recorded so far, such as
+// the angles of the "ServoWrite" ones
+func (t *gpioTestAdaptor) WrittenValues(op string) []byte {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	values := []byte{}
+	for _, w := range t.written {
+		if w.op == op {
+			values = append(values, w.val)
+		}
+	}
+	return values
+}
+
+func (t *gpioTestAdaptor) ServoWrite(pin string, val byte) (err error) {
 	t.mtx.Lock()
 	defer t.mtx.Unlock()
+	t.written = append(t.written, gpioTestWrite{"ServoWrite", pin, val})
 	return t.testAdaptorServoWrite()
 }
-func (t *gpioTestAdaptor) PwmWrite(string, byte) (err error) {
+func (t *gpioTestAdaptor) PwmWrite(pin string, val byte) (err error) {
 	t.mtx.Lock()
 	defer t.mtx.Unlock()
+	t.written = append(t.written, gpioTestWrite{"PwmWrite", pin, val})
 	return t.testAdaptorPwmWrite()
 }
 func (t *gpioTestAdaptor) AnalogRead(string) (val int, err error) {
@@ -72,9 +104,10 @@ func (t *gpioTestAdaptor) DigitalRead(string) (val int, err error) {
 	defer t.mtx.Unlock()
 	return t.testAdaptorDigitalRead()
 }
-func (t *gpioTestAdaptor) DigitalWrite(string, byte) (err error) {
+func (t *gpioTestAdaptor) DigitalWrite(pin string, val byte) (err error) {
 	t.mtx.Lock()
 	defer t.mtx.Unlock()
+	t.written = append(t.written, gpioTestWrite{"DigitalWrite", pin, val})
 	return t.testAdaptorDigitalWrite()
 }
 func (t *gpioTestAdaptor) Connect() (err error)  { return }
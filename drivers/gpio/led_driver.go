@@ -100,10 +100,16 @@ func (l *LedDriver) Toggle() (err error) {
 	return
 }
 
-// Brightness sets the led to the specified level of brightness
+// Brightness sets the led to the specified level of brightness. The led is
+// considered On for any level above 0, so Toggle turns it Off afterwards.
 func (l *LedDriver) Brightness(level byte) (err error) {
-	if writer, ok := l.connection.(PwmWriter); ok {
-		return writer.PwmWrite(l.Pin(), level)
+	writer, ok := l.connection.(PwmWriter)
+	if !ok {
+		return ErrPwmWriteUnsupported
 	}
-	return ErrPwmWriteUnsupported
+	if err = writer.PwmWrite(l.Pin(), level); err != nil {
+		return
+	}
+	l.high = level > 0
+	return
 }
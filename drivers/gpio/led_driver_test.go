@@ -80,6 +80,20 @@ func TestLedDriverBrightness(t *testing.T) {
 	gobottest.Assert(t, d.Brightness(150), errors.New("pwm error"))
 }
 
+func TestLedDriverBrightnessState(t *testing.T) {
+	d := initTestLedDriver()
+	gobottest.Assert(t, d.Brightness(150), nil)
+	gobottest.Assert(t, d.State(), true)
+	d.Toggle()
+	gobottest.Assert(t, d.State(), false)
+	gobottest.Assert(t, d.Brightness(0), nil)
+	gobottest.Assert(t, d.State(), false)
+
+	d = NewLedDriver(&gpioTestDigitalWriter{}, "1")
+	gobottest.Assert(t, d.Brightness(150), ErrPwmWriteUnsupported)
+	gobottest.Assert(t, d.State(), false)
+}
+
 func TestLEDDriverDefaultName(t *testing.T) {
 	a := newGpioTestAdaptor()
 	d := NewLedDriver(a, "1")
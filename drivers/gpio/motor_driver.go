@@ -1,6 +1,8 @@
 package gpio
 
 import (
+	"errors"
+
 	"gobot.io/x/gobot"
 )
 
@@ -17,11 +19,23 @@ type MotorDriver struct {
 	CurrentSpeed     byte
 	CurrentMode      string
 	CurrentDirection string
+	gobot.Commander
 }
 
 // NewMotorDriver return a new MotorDriver given a DigitalWriter and pin
+//
+// Adds the following API Commands:
+//	"On" - See MotorDriver.On
+//	"Off" - See MotorDriver.Off
+//	"Toggle" - See MotorDriver.Toggle
+//	"Min" - See MotorDriver.Min
+//	"Max" - See MotorDriver.Max
+//	"Speed" - See MotorDriver.Speed
+//	"Forward" - See MotorDriver.Forward
+//	"Backward" - See MotorDriver.Backward
+//	"Direction" - See MotorDriver.Direction
 func NewMotorDriver(a DigitalWriter, speedPin string) *MotorDriver {
-	return &MotorDriver{
+	m := &MotorDriver{
 		name:             gobot.DefaultName("Motor"),
 		connection:       a,
 		SpeedPin:         speedPin,
@@ -29,7 +43,69 @@ func NewMotorDriver(a DigitalWriter, speedPin string) *MotorDriver {
 		CurrentSpeed:     0,
 		CurrentMode:      "digital",
 		CurrentDirection: "forward",
+		Commander:        gobot.NewCommander(),
+	}
+
+	m.AddCommand("On", func(params map[string]interface{}) interface{} {
+		return m.On()
+	})
+	m.AddCommand("Off", func(params map[string]interface{}) interface{} {
+		return m.Off()
+	})
+	m.AddCommand("Toggle", func(params map[string]interface{}) interface{} {
+		return m.Toggle()
+	})
+	m.AddCommand("Min", func(params map[string]interface{}) interface{} {
+		return m.Min()
+	})
+	m.AddCommand("Max", func(params map[string]interface{}) interface{} {
+		return m.Max()
+	})
+	m.AddCommand("Speed", func(params map[string]interface{}) interface{} {
+		speed, err := speedParam(params)
+		if err != nil {
+			return err
+		}
+		return m.Speed(speed)
+	})
+	m.AddCommand("Forward", func(params map[string]interface{}) interface{} {
+		speed, err := speedParam(params)
+		if err != nil {
+			return err
+		}
+		return m.Forward(speed)
+	})
+	m.AddCommand("Backward", func(params map[string]interface{}) interface{} {
+		speed, err := speedParam(params)
+		if err != nil {
+			return err
+		}
+		return m.Backward(speed)
+	})
+	m.AddCommand("Direction", func(params map[string]interface{}) interface{} {
+		direction, ok := params["direction"].(string)
+		if !ok {
+			return errors.New("Missing or invalid direction parameter")
+		}
+		return m.Direction(direction)
+	})
+
+	return m
+}
+
+// speedParam returns the "speed" command param clamped to 0-255
+func speedParam(params map[string]interface{}) (byte, error) {
+	speed, ok := params["speed"].(float64)
+	if !ok {
+		return 0, errors.New("Missing or invalid speed parameter")
+	}
+	if speed < 0 {
+		return 0, nil
+	}
+	if speed > 255 {
+		return 255, nil
 	}
+	return byte(speed), nil
 }
 
 // Name returns the MotorDrivers name
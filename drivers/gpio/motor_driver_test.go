@@ -1,6 +1,7 @@
 package gpio
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -126,6 +127,47 @@ func TestMotorDriverDigital(t *testing.T) {
 	gobottest.Assert(t, d.CurrentState, uint8(0))
 }
 
+func TestMotorDriverCommands(t *testing.T) {
+	d := initTestMotorDriver()
+	d.ForwardPin = "2"
+	d.BackwardPin = "3"
+
+	gobottest.Assert(t, d.Command("Speed")(map[string]interface{}{"speed": 300.0}), nil)
+	gobottest.Assert(t, d.CurrentSpeed, uint8(255))
+
+	gobottest.Assert(t, d.Command("Backward")(map[string]interface{}{"speed": -5.0}), nil)
+	gobottest.Assert(t, d.CurrentSpeed, uint8(0))
+	gobottest.Assert(t, d.CurrentDirection, "backward")
+
+	gobottest.Assert(t, d.Command("Forward")(map[string]interface{}{"speed": 100.0}), nil)
+	gobottest.Assert(t, d.CurrentSpeed, uint8(100))
+	gobottest.Assert(t, d.CurrentDirection, "forward")
+
+	gobottest.Assert(t, d.Command("Direction")(map[string]interface{}{"direction": "backward"}), nil)
+	gobottest.Assert(t, d.CurrentDirection, "backward")
+
+	gobottest.Assert(t, d.Command("Max")(nil), nil)
+	gobottest.Assert(t, d.CurrentSpeed, uint8(255))
+	gobottest.Assert(t, d.Command("Min")(nil), nil)
+	gobottest.Assert(t, d.IsOff(), true)
+	gobottest.Assert(t, d.Command("Toggle")(nil), nil)
+	gobottest.Assert(t, d.IsOn(), true)
+	gobottest.Assert(t, d.Command("Off")(nil), nil)
+	gobottest.Assert(t, d.IsOff(), true)
+	gobottest.Assert(t, d.Command("On")(nil), nil)
+	gobottest.Assert(t, d.IsOn(), true)
+}
+
+func TestMotorDriverCommandsInvalidParams(t *testing.T) {
+	d := initTestMotorDriver()
+	for _, name := range []string{"Speed", "Forward", "Backward"} {
+		gobottest.Assert(t, d.Command(name)(nil), errors.New("Missing or invalid speed parameter"))
+		gobottest.Assert(t, d.Command(name)(map[string]interface{}{"speed": "fast"}), errors.New("Missing or invalid speed parameter"))
+	}
+	gobottest.Assert(t, d.Command("Direction")(map[string]interface{}{"direction": 1.0}), errors.New("Missing or invalid direction parameter"))
+	gobottest.Assert(t, d.CurrentSpeed, uint8(0))
+}
+
 func TestMotorDriverDefaultName(t *testing.T) {
 	d := initTestMotorDriver()
 	gobottest.Assert(t, strings.HasPrefix(d.Name(), "Motor"), true)
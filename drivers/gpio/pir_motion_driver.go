@@ -13,6 +13,7 @@ type PIRMotionDriver struct {
 	name       string
 	halt       chan bool
 	interval   time.Duration
+	cooldown   time.Duration
 	connection DigitalReader
 	gobot.Eventer
 }
@@ -44,6 +45,11 @@ func NewPIRMotionDriver(a DigitalReader, pin string, v ...time.Duration) *PIRMot
 	return b
 }
 
+// SetCooldown sets how long the sensor must stop detecting motion before the
+// MotionStopped event is sent, so a sensor chattering between its two states
+// only sends one MotionDetected and MotionStopped pair. Defaults to 0.
+func (p *PIRMotionDriver) SetCooldown(cooldown time.Duration) { p.cooldown = cooldown }
+
 // Start starts the PIRMotionDriver and polls the state of the sensor at the given interval.
 //
 // Emits the Events:
@@ -54,9 +60,11 @@ func NewPIRMotionDriver(a DigitalReader, pin string, v ...time.Duration) *PIRMot
 // The PIRMotionDriver will send the MotionDetected event over and over,
 // just as long as motion is still being detected.
 // It will only send the MotionStopped event once, however, until
-// motion starts being detected again
+// motion starts being detected again. When a cooldown is set, motion is
+// only considered stopped once none has been detected for the cooldown.
 func (p *PIRMotionDriver) Start() (err error) {
 	go func() {
+		var lastMotion time.Time
 		for {
 			newValue, err := p.connection.DigitalRead(p.Pin())
 			if err != nil {
@@ -64,12 +72,13 @@ func (p *PIRMotionDriver) Start() (err error) {
 			}
 			switch newValue {
 			case 1:
+				lastMotion = time.Now()
 				if !p.Active {
 					p.Active = true
 					p.Publish(MotionDetected, newValue)
 				}
 			case 0:
-				if p.Active {
+				if p.Active && time.Since(lastMotion) >= p.cooldown {
 					p.Active = false
 					p.Publish(MotionStopped, newValue)
 				}
@@ -89,6 +89,53 @@ func TestPIRMotionDriverStart(t *testing.T) {
 	}
 }
 
+func TestPIRMotionDriverCooldown(t *testing.T) {
+	a := newGpioTestAdaptor()
+	d := NewPIRMotionDriver(a, "1")
+	d.SetCooldown(100 * time.Millisecond)
+
+	detected := make(chan bool, 10)
+	stopped := make(chan bool, 10)
+	d.On(MotionDetected, func(data interface{}) {
+		detected <- true
+	})
+	d.On(MotionStopped, func(data interface{}) {
+		stopped <- true
+	})
+
+	value := func(v int) {
+		a.TestAdaptorDigitalRead(func() (val int, err error) {
+			return v, nil
+		})
+	}
+
+	value(1)
+	gobottest.Assert(t, d.Start(), nil)
+	select {
+	case <-detected:
+	case <-time.After(motionTestDelay * time.Millisecond):
+		t.Errorf("PIRMotionDriver Event \"MotionDetected\" was not published")
+	}
+
+	// chatter within the cooldown is suppressed
+	for i := 0; i < 3; i++ {
+		value(0)
+		<-time.After(20 * time.Millisecond)
+		value(1)
+		<-time.After(20 * time.Millisecond)
+	}
+	value(0)
+
+	select {
+	case <-stopped:
+	case <-time.After(2 * motionTestDelay * time.Millisecond):
+		t.Errorf("PIRMotionDriver Event \"MotionStopped\" was not published")
+	}
+	gobottest.Assert(t, len(detected), 0)
+	gobottest.Assert(t, len(stopped), 0)
+	d.Halt()
+}
+
 func TestPIRDriverDefaultName(t *testing.T) {
 	d := initTestPIRMotionDriver()
 	gobottest.Assert(t, strings.HasPrefix(d.Name(), "PIR"), true)
@@ -8,6 +8,10 @@ type RelayDriver struct {
 	name       string
 	connection DigitalWriter
 	high       bool
+
+	// Inverted writes a low level to turn the relay on, for the many relay
+	// boards which are active low
+	Inverted bool
 	gobot.Commander
 }
 
@@ -61,29 +65,38 @@ func (l *RelayDriver) Connection() gobot.Connection {
 	return l.connection.(gobot.Connection)
 }
 
-// State return true if the relay is On and false if the relay is Off
+// State return true if the relay is On and false if the relay is Off,
+// whether it is Inverted or not
 func (l *RelayDriver) State() bool {
 	return l.high
 }
 
-// On sets the relay to a high state.
+// On sets the relay to a high state, or a low state when Inverted.
 func (l *RelayDriver) On() (err error) {
-	if err = l.connection.DigitalWrite(l.Pin(), 1); err != nil {
+	if err = l.write(true); err != nil {
 		return
 	}
 	l.high = true
 	return
 }
 
-// Off sets the relay to a low state.
+// Off sets the relay to a low state, or a high state when Inverted.
 func (l *RelayDriver) Off() (err error) {
-	if err = l.connection.DigitalWrite(l.Pin(), 0); err != nil {
+	if err = l.write(false); err != nil {
 		return
 	}
 	l.high = false
 	return
 }
 
+func (l *RelayDriver) write(on bool) error {
+	level := byte(0)
+	if on != l.Inverted {
+		level = 1
+	}
+	return l.connection.DigitalWrite(l.Pin(), level)
+}
+
 // Toggle sets the relay to the opposite of it's current state
 func (l *RelayDriver) Toggle() (err error) {
 	if l.State() {
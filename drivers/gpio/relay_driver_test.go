@@ -63,3 +63,22 @@ func TestRelayDriverCommands(t *testing.T) {
 	gobottest.Assert(t, d.Command("Toggle")(nil), nil)
 	gobottest.Assert(t, d.State(), false)
 }
+
+func TestRelayDriverInverted(t *testing.T) {
+	a := newGpioTestAdaptor()
+	d := NewRelayDriver(a, "1")
+	gobottest.Assert(t, d.On(), nil)
+	gobottest.Assert(t, d.Off(), nil)
+
+	d.Inverted = true
+	gobottest.Assert(t, d.On(), nil)
+	gobottest.Assert(t, d.State(), true)
+	gobottest.Assert(t, d.Toggle(), nil)
+	gobottest.Assert(t, d.State(), false)
+	gobottest.Assert(t, a.Written(), []gpioTestWrite{
+		{"DigitalWrite", "1", 1},
+		{"DigitalWrite", "1", 0},
+		{"DigitalWrite", "1", 0},
+		{"DigitalWrite", "1", 1},
+	})
+}
@@ -1,6 +1,10 @@
 package gpio
 
-import "gobot.io/x/gobot"
+import (
+	"image/color"
+
+	"gobot.io/x/gobot"
+)
 
 // RgbLedDriver represents a digital RGB Led
 type RgbLedDriver struct {
@@ -13,6 +17,10 @@ type RgbLedDriver struct {
 	name       string
 	connection DigitalWriter
 	high       bool
+
+	// CommonAnode inverts the PWM levels, for LEDs whose common pin is
+	// wired to the supply instead of the ground
+	CommonAnode bool
 	gobot.Commander
 }
 
@@ -36,9 +44,9 @@ func NewRgbLedDriver(a DigitalWriter, redPin string, greenPin string, bluePin st
 	}
 
 	l.AddCommand("SetRGB", func(params map[string]interface{}) interface{} {
-		r := byte(params["r"].(int))
-		g := byte(params["g"].(int))
-		b := byte(params["b"].(int))
+		r := colorParam(params["r"])
+		g := colorParam(params["g"])
+		b := colorParam(params["b"])
 		return l.SetRGB(r, g, b)
 	})
 
@@ -127,7 +135,8 @@ func (l *RgbLedDriver) Off() (err error) {
 	return
 }
 
-// Toggle sets the led to the opposite of it's current state
+// Toggle sets the led to the opposite of it's current state, turning it on
+// with the last color set which was not black
 func (l *RgbLedDriver) Toggle() (err error) {
 	if l.State() {
 		err = l.Off()
@@ -139,17 +148,50 @@ func (l *RgbLedDriver) Toggle() (err error) {
 
 // SetLevel sets the led to the specified color level
 func (l *RgbLedDriver) SetLevel(pin string, level byte) (err error) {
+	if l.CommonAnode {
+		level = 255 - level
+	}
 	if writer, ok := l.connection.(PwmWriter); ok {
 		return writer.PwmWrite(pin, level)
 	}
 	return ErrPwmWriteUnsupported
 }
 
-// SetRGB sets the Red Green Blue value of the LED.
+// SetRGB sets the Red Green Blue value of the LED. Black turns the LED off
+// and keeps the previous color for Toggle.
 func (l *RgbLedDriver) SetRGB(r, g, b byte) error {
+	if r == 0 && g == 0 && b == 0 {
+		return l.Off()
+	}
+
 	l.redColor = r
 	l.greenColor = g
 	l.blueColor = b
 
 	return l.On()
 }
+
+// SetColor sets the LED to the color c, see SetRGB.
+func (l *RgbLedDriver) SetColor(c color.Color) error {
+	r, g, b, _ := c.RGBA()
+	return l.SetRGB(byte(r>>8), byte(g>>8), byte(b>>8))
+}
+
+// colorParam converts a color command parameter, clamped to 0-255
+func colorParam(param interface{}) byte {
+	var value float64
+	switch param.(type) {
+	case int:
+		value = float64(param.(int))
+	case float64:
+		value = param.(float64)
+	}
+
+	if value < 0 {
+		return 0
+	}
+	if value > 255 {
+		return 255
+	}
+	return byte(value)
+}
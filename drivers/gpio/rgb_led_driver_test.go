@@ -2,6 +2,7 @@ package gpio
 
 import (
 	"errors"
+	"image/color"
 	"strings"
 	"testing"
 
@@ -86,6 +87,50 @@ func TestRgbLedDriverSetLevel(t *testing.T) {
 	gobottest.Assert(t, d.SetLevel("1", 150), errors.New("pwm error"))
 }
 
+// rgbTestPwmWriter records the last level written to each pin
+type rgbTestPwmWriter struct {
+	gpioTestDigitalWriter
+	levels map[string]byte
+}
+
+func (t *rgbTestPwmWriter) PwmWrite(pin string, level byte) (err error) {
+	t.levels[pin] = level
+	return
+}
+
+func TestRgbLedDriverSetRGB(t *testing.T) {
+	a := &rgbTestPwmWriter{levels: map[string]byte{}}
+	d := NewRgbLedDriver(a, "1", "2", "3")
+
+	gobottest.Assert(t, d.SetRGB(10, 20, 30), nil)
+	gobottest.Assert(t, a.levels, map[string]byte{"1": 10, "2": 20, "3": 30})
+
+	gobottest.Assert(t, d.SetColor(color.RGBA{R: 0xff, G: 0x80, B: 0x00, A: 0xff}), nil)
+	gobottest.Assert(t, a.levels, map[string]byte{"1": 0xff, "2": 0x80, "3": 0x00})
+
+	// black turns the led off, Toggle restores the last color
+	gobottest.Assert(t, d.SetRGB(0, 0, 0), nil)
+	gobottest.Assert(t, d.State(), false)
+	gobottest.Assert(t, d.Toggle(), nil)
+	gobottest.Assert(t, d.State(), true)
+	gobottest.Assert(t, a.levels, map[string]byte{"1": 0xff, "2": 0x80, "3": 0x00})
+
+	// values are clamped, and sent by the API as float64
+	gobottest.Assert(t, d.Command("SetRGB")(map[string]interface{}{"r": 300.0, "g": -5.0, "b": 12.0}), nil)
+	gobottest.Assert(t, a.levels, map[string]byte{"1": 255, "2": 0, "3": 12})
+}
+
+func TestRgbLedDriverCommonAnode(t *testing.T) {
+	a := &rgbTestPwmWriter{levels: map[string]byte{}}
+	d := NewRgbLedDriver(a, "1", "2", "3")
+	d.CommonAnode = true
+
+	gobottest.Assert(t, d.SetRGB(255, 100, 0), nil)
+	gobottest.Assert(t, a.levels, map[string]byte{"1": 0, "2": 155, "3": 255})
+	gobottest.Assert(t, d.Off(), nil)
+	gobottest.Assert(t, a.levels, map[string]byte{"1": 255, "2": 255, "3": 255})
+}
+
 func TestRgbLedDriverDefaultName(t *testing.T) {
 	a := newGpioTestAdaptor()
 	d := NewRgbLedDriver(a, "1", "2", "3")
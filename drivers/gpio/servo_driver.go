@@ -1,6 +1,11 @@
 package gpio
 
-import "gobot.io/x/gobot"
+import (
+	"sync"
+	"time"
+
+	"gobot.io/x/gobot"
+)
 
 // ServoDriver Represents a Servo
 type ServoDriver struct {
@@ -8,7 +13,16 @@ type ServoDriver struct {
 	pin        string
 	connection ServoWriter
 	gobot.Commander
+	// CurrentAngle is the last value written to the ServoWriter, 0-180
 	CurrentAngle byte
+	// MinAngle and MaxAngle are the angle range of the servo in degrees,
+	// such as 0-270, which is mapped onto the 0-180 range of the
+	// ServoWriter. Angles outside of it are rejected. Defaults to 0-180.
+	MinAngle int
+	MaxAngle int
+	angle    int
+	mutex    sync.Mutex
+	sweep    *gobot.Ticker
 }
 
 // NewServoDriver returns a new ServoDriver given a ServoWriter and pin.
@@ -18,6 +32,7 @@ type ServoDriver struct {
 //		"Min" - See ServoDriver.Min
 //		"Center" - See ServoDriver.Center
 //		"Max" - See ServoDriver.Max
+//		"Sweep" - See ServoDriver.Sweep
 func NewServoDriver(a ServoWriter, pin string) *ServoDriver {
 	s := &ServoDriver{
 		name:         gobot.DefaultName("Servo"),
@@ -25,11 +40,13 @@ func NewServoDriver(a ServoWriter, pin string) *ServoDriver {
 		pin:          pin,
 		Commander:    gobot.NewCommander(),
 		CurrentAngle: 0,
+		MinAngle:     0,
+		MaxAngle:     180,
 	}
 
 	s.AddCommand("Move", func(params map[string]interface{}) interface{} {
-		angle := byte(params["angle"].(float64))
-		return s.Move(angle)
+		angle := int(params["angle"].(float64))
+		return s.MoveAngle(angle)
 	})
 	s.AddCommand("Min", func(params map[string]interface{}) interface{} {
 		return s.Min()
@@ -40,6 +57,13 @@ func NewServoDriver(a ServoWriter, pin string) *ServoDriver {
 	s.AddCommand("Max", func(params map[string]interface{}) interface{} {
 		return s.Max()
 	})
+	s.AddCommand("Sweep", func(params map[string]interface{}) interface{} {
+		from := int(params["from"].(float64))
+		to := int(params["to"].(float64))
+		duration := time.Duration(params["duration"].(float64)) * time.Millisecond
+		_, err := s.Sweep(from, to, duration)
+		return err
+	})
 
 	return s
 
@@ -61,28 +85,122 @@ func (s *ServoDriver) Connection() gobot.Connection { return s.connection.(gobot
 func (s *ServoDriver) Start() (err error) { return }
 
 // Halt implements the Driver interface
-func (s *ServoDriver) Halt() (err error) { return }
+func (s *ServoDriver) Halt() (err error) {
+	s.stopSweep()
+	return
+}
 
-// Move sets the servo to the specified angle. Acceptable angles are 0-180
+// Move sets the servo to the specified angle. Acceptable angles are
+// MinAngle-MaxAngle, 0-180 by default. Use MoveAngle for angles over 255.
 func (s *ServoDriver) Move(angle uint8) (err error) {
-	if !(angle >= 0 && angle <= 180) {
+	return s.MoveAngle(int(angle))
+}
+
+// MoveAngle sets the servo to the specified angle in degrees, between
+// MinAngle and MaxAngle
+func (s *ServoDriver) MoveAngle(angle int) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.write(angle)
+}
+
+// write maps angle onto the range of the ServoWriter and writes it, with
+// the mutex held
+func (s *ServoDriver) write(angle int) (err error) {
+	span := s.MaxAngle - s.MinAngle
+	if span <= 0 || angle < s.MinAngle || angle > s.MaxAngle {
 		return ErrServoOutOfRange
 	}
-	s.CurrentAngle = angle
-	return s.connection.ServoWrite(s.Pin(), angle)
+	value := byte(((angle-s.MinAngle)*180 + span/2) / span)
+	s.angle = angle
+	s.CurrentAngle = value
+	return s.connection.ServoWrite(s.Pin(), value)
+}
+
+// Angle returns the last angle the servo was set to, in degrees
+func (s *ServoDriver) Angle() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.angle
 }
 
 // Min sets the servo to it's minimum position
 func (s *ServoDriver) Min() (err error) {
-	return s.Move(0)
+	return s.MoveAngle(s.MinAngle)
 }
 
 // Center sets the servo to it's center position
 func (s *ServoDriver) Center() (err error) {
-	return s.Move(90)
+	return s.MoveAngle(s.MinAngle + (s.MaxAngle-s.MinAngle)/2)
 }
 
 // Max sets the servo to its maximum position
 func (s *ServoDriver) Max() (err error) {
-	return s.Move(180)
+	return s.MoveAngle(s.MaxAngle)
+}
+
+// Sweep moves the servo from one angle to the other one degree at a time,
+// spreading the steps evenly over duration. Any sweep already in progress is
+// stopped first. The returned func stops the sweep at its current angle.
+func (s *ServoDriver) Sweep(from, to int, duration time.Duration) (stop func(), err error) {
+	s.stopSweep()
+	stop = func() {}
+
+	if to < s.MinAngle || to > s.MaxAngle {
+		return stop, ErrServoOutOfRange
+	}
+	if err = s.MoveAngle(from); err != nil {
+		return
+	}
+
+	steps := to - from
+	step := 1
+	if steps < 0 {
+		steps, step = -steps, -1
+	}
+	if steps == 0 {
+		return
+	}
+	interval := duration / time.Duration(steps)
+	if interval <= 0 {
+		return stop, s.MoveAngle(to)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	angle := from
+	var ticker *gobot.Ticker
+	ticker = gobot.Every(interval, func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if s.sweep != ticker {
+			return
+		}
+		angle += step
+		if angle == to {
+			s.sweep.Stop()
+			s.sweep = nil
+		}
+		s.write(angle)
+	})
+	s.sweep = ticker
+
+	return func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if s.sweep == ticker {
+			s.sweep.Stop()
+			s.sweep = nil
+		}
+	}, nil
+}
+
+func (s *ServoDriver) stopSweep() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.sweep != nil {
+		s.sweep.Stop()
+		s.sweep = nil
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"gobot.io/x/gobot"
 	"gobot.io/x/gobot/gobottest"
@@ -59,6 +60,97 @@ func TestServoDriverMove(t *testing.T) {
 	gobottest.Assert(t, err, ErrServoOutOfRange)
 }
 
+func TestServoDriverMoveWritesAngle(t *testing.T) {
+	a := newGpioTestAdaptor()
+	d := NewServoDriver(a, "1")
+	d.Min()
+	d.Center()
+	d.Max()
+	d.Move(45)
+	// ServoWriters take the angle in degrees, it must not be scaled
+	gobottest.Assert(t, a.WrittenValues("ServoWrite"), []byte{0, 90, 180, 45})
+}
+
+func TestServoDriverAngleRange(t *testing.T) {
+	a := newGpioTestAdaptor()
+	d := NewServoDriver(a, "1")
+	d.MinAngle = 0
+	d.MaxAngle = 90
+	gobottest.Assert(t, d.Min(), nil)
+	gobottest.Assert(t, d.Center(), nil)
+	gobottest.Assert(t, d.Max(), nil)
+	// the range of the servo is mapped onto the 0-180 of the ServoWriter
+	gobottest.Assert(t, a.WrittenValues("ServoWrite"), []byte{0, 90, 180})
+	gobottest.Assert(t, d.Angle(), 90)
+	gobottest.Assert(t, d.CurrentAngle, uint8(180))
+
+	gobottest.Assert(t, d.Move(91), ErrServoOutOfRange)
+	gobottest.Assert(t, d.Angle(), 90)
+
+	a = newGpioTestAdaptor()
+	d = NewServoDriver(a, "1")
+	d.MaxAngle = 270
+	gobottest.Assert(t, d.MoveAngle(270), nil)
+	gobottest.Assert(t, d.MoveAngle(135), nil)
+	gobottest.Assert(t, d.Move(45), nil)
+	gobottest.Assert(t, a.WrittenValues("ServoWrite"), []byte{180, 90, 30})
+	gobottest.Assert(t, d.MoveAngle(-1), ErrServoOutOfRange)
+	gobottest.Assert(t, d.MoveAngle(271), ErrServoOutOfRange)
+
+	d.MinAngle, d.MaxAngle = 90, 90
+	gobottest.Assert(t, d.MoveAngle(90), ErrServoOutOfRange)
+}
+
+func TestServoDriverSweep(t *testing.T) {
+	a := newGpioTestAdaptor()
+	d := NewServoDriver(a, "1")
+	_, err := d.Sweep(10, 5, 25*time.Millisecond)
+	gobottest.Assert(t, err, nil)
+	time.Sleep(75 * time.Millisecond)
+	gobottest.Assert(t, a.WrittenValues("ServoWrite"), []byte{10, 9, 8, 7, 6, 5})
+
+	_, err = d.Sweep(10, 200, time.Second)
+	gobottest.Assert(t, err, ErrServoOutOfRange)
+}
+
+func TestServoDriverSweepStop(t *testing.T) {
+	a := newGpioTestAdaptor()
+	d := NewServoDriver(a, "1")
+	stop, err := d.Sweep(0, 180, 10*time.Second)
+	gobottest.Assert(t, err, nil)
+	stop()
+	time.Sleep(100 * time.Millisecond)
+	gobottest.Assert(t, a.WrittenValues("ServoWrite"), []byte{0})
+
+	// a new sweep preempts the running one
+	d.Sweep(0, 180, 10*time.Second)
+	d.Sweep(90, 90, time.Second)
+	time.Sleep(100 * time.Millisecond)
+	gobottest.Assert(t, a.WrittenValues("ServoWrite"), []byte{0, 0, 90})
+}
+
+func TestServoDriverMoveDuringSweep(t *testing.T) {
+	a := newGpioTestAdaptor()
+	d := NewServoDriver(a, "1")
+	d.Sweep(0, 180, 90*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		gobottest.Assert(t, d.Move(90), nil)
+		gobottest.Assert(t, d.Angle() <= 180, true)
+		time.Sleep(time.Millisecond)
+	}
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestServoDriverSweepCommand(t *testing.T) {
+	a := newGpioTestAdaptor()
+	d := NewServoDriver(a, "1")
+	err := d.Command("Sweep")(map[string]interface{}{"from": 3.0, "to": 1.0, "duration": 10.0})
+	gobottest.Assert(t, err, nil)
+	time.Sleep(50 * time.Millisecond)
+	gobottest.Assert(t, a.WrittenValues("ServoWrite"), []byte{3, 2, 1})
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
 func TestServoDriverMin(t *testing.T) {
 	d := initTestServoDriver()
 	d.Min()
@@ -1,6 +1,7 @@
 package i2c
 
 import (
+	"errors"
 	"fmt"
 
 	"gobot.io/x/gobot"
@@ -62,6 +63,27 @@ func NewBlinkMDriver(a Connector, options ...func(Config)) *BlinkMDriver {
 		return map[string]interface{}{"color": color, "err": err}
 	})
 
+	b.AddCommand("PlayLightScript", func(params map[string]interface{}) interface{} {
+		id := byte(params["id"].(float64))
+		repeats := byte(params["repeats"].(float64))
+		startLine := byte(params["startLine"].(float64))
+		return b.PlayLightScript(id, repeats, startLine)
+	})
+
+	b.AddCommand("StopScript", func(params map[string]interface{}) interface{} {
+		return b.StopScript()
+	})
+
+	b.AddCommand("SetFadeSpeed", func(params map[string]interface{}) interface{} {
+		speed := byte(params["speed"].(float64))
+		return b.SetFadeSpeed(speed)
+	})
+
+	b.AddCommand("SetTimeToStop", func(params map[string]interface{}) interface{} {
+		time := byte(params["time"].(float64))
+		return b.SetTimeToStop(time)
+	})
+
 	return b
 }
 
@@ -136,3 +158,67 @@ func (b *BlinkMDriver) Color() (color []byte, err error) {
 	}
 	return []byte{data[0], data[1], data[2]}, nil
 }
+
+// PlayLightScript plays the light script with the given id, starting at
+// startLine. A repeats value of 0 loops the script forever.
+func (b *BlinkMDriver) PlayLightScript(id byte, repeats byte, startLine byte) (err error) {
+	if _, err = b.connection.Write([]byte("p")); err != nil {
+		return
+	}
+	_, err = b.connection.Write([]byte{id, repeats, startLine})
+	return
+}
+
+// StopScript stops the light script currently playing
+func (b *BlinkMDriver) StopScript() (err error) {
+	_, err = b.connection.Write([]byte("o"))
+	return
+}
+
+// SetFadeSpeed sets the speed at which Fade and light scripts change color,
+// from 1 (slowest) to 255 (instant). The BlinkM forgets the fade speed on
+// power loss, so set it before fading to get repeatable results.
+func (b *BlinkMDriver) SetFadeSpeed(speed byte) (err error) {
+	if speed == 0 {
+		return errors.New("Fade speed must be between 1 and 255")
+	}
+	if _, err = b.connection.Write([]byte("f")); err != nil {
+		return
+	}
+	_, err = b.connection.Write([]byte{speed})
+	return
+}
+
+// SetTimeToStop sets the time adjustment applied to the durations of light
+// script lines. It is a signed value, 0 plays scripts at their normal speed.
+func (b *BlinkMDriver) SetTimeToStop(time byte) (err error) {
+	if _, err = b.connection.Write([]byte("t")); err != nil {
+		return
+	}
+	_, err = b.connection.Write([]byte{time})
+	return
+}
+
+// SetAddress changes the I2C address of the BlinkM to address, which must be
+// between 1 and 127. The BlinkM stores the new address in its EEPROM and the
+// driver uses it from then on.
+func (b *BlinkMDriver) SetAddress(address byte) (err error) {
+	if address == 0 || address > 0x7f {
+		return errors.New("Address must be between 1 and 127")
+	}
+	if _, err = b.connection.Write([]byte("A")); err != nil {
+		return
+	}
+	if _, err = b.connection.Write([]byte{address, 0xd0, 0x0d, address}); err != nil {
+		return
+	}
+
+	bus := b.GetBusOrDefault(b.connector.GetDefaultBus())
+	connection, err := b.connector.GetConnection(int(address), bus)
+	if err != nil {
+		return
+	}
+	b.connection = connection
+	b.WithAddress(int(address))
+	return
+}
@@ -214,6 +214,83 @@ func TestBlinkMDriverRGB(t *testing.T) {
 
 }
 
+func TestBlinkMDriverPlayLightScript(t *testing.T) {
+	blinkM, adaptor := initTestBlinkDriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, blinkM.Start(), nil)
+	adaptor.written = []byte{}
+
+	gobottest.Assert(t, blinkM.PlayLightScript(10, 0, 2), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x70, 10, 0, 2})
+
+	adaptor.written = []byte{}
+	result := blinkM.Command("PlayLightScript")(map[string]interface{}{"id": 5.0, "repeats": 3.0, "startLine": 0.0})
+	gobottest.Assert(t, result, nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x70, 5, 3, 0})
+
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	gobottest.Assert(t, blinkM.PlayLightScript(10, 0, 0), errors.New("write error"))
+}
+
+func TestBlinkMDriverStopScript(t *testing.T) {
+	blinkM, adaptor := initTestBlinkDriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, blinkM.Start(), nil)
+	adaptor.written = []byte{}
+
+	gobottest.Assert(t, blinkM.Command("StopScript")(nil), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x6f})
+}
+
+func TestBlinkMDriverSetFadeSpeed(t *testing.T) {
+	blinkM, adaptor := initTestBlinkDriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, blinkM.Start(), nil)
+	adaptor.written = []byte{}
+
+	gobottest.Assert(t, blinkM.Command("SetFadeSpeed")(map[string]interface{}{"speed": 20.0}), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x66, 20})
+
+	adaptor.written = []byte{}
+	gobottest.Assert(t, blinkM.SetFadeSpeed(0), errors.New("Fade speed must be between 1 and 255"))
+	gobottest.Assert(t, adaptor.written, []byte{})
+}
+
+func TestBlinkMDriverSetTimeToStop(t *testing.T) {
+	blinkM, adaptor := initTestBlinkDriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, blinkM.Start(), nil)
+	adaptor.written = []byte{}
+
+	gobottest.Assert(t, blinkM.Command("SetTimeToStop")(map[string]interface{}{"time": 5.0}), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x74, 5})
+
+	adaptor.i2cWriteImpl = func([]byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	gobottest.Assert(t, blinkM.SetTimeToStop(5), errors.New("write error"))
+}
+
+func TestBlinkMDriverSetAddress(t *testing.T) {
+	blinkM, adaptor := initTestBlinkDriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, blinkM.Start(), nil)
+	adaptor.written = []byte{}
+
+	gobottest.Assert(t, blinkM.SetAddress(0x0a), nil)
+	gobottest.Assert(t, adaptor.written, []byte{0x41, 0x0a, 0xd0, 0x0d, 0x0a})
+	gobottest.Assert(t, blinkM.GetAddressOrDefault(blinkmAddress), 0x0a)
+
+	gobottest.Assert(t, blinkM.SetAddress(0), errors.New("Address must be between 1 and 127"))
+	gobottest.Assert(t, blinkM.SetAddress(0x80), errors.New("Address must be between 1 and 127"))
+
+	adaptor.Testi2cConnectErr(true)
+	gobottest.Assert(t, blinkM.SetAddress(0x0b), errors.New("Invalid i2c connection"))
+	gobottest.Assert(t, blinkM.GetAddressOrDefault(blinkmAddress), 0x0a)
+}
+
 func TestBlinkMDriverSetName(t *testing.T) {
 	d := initTestBlinkMDriver()
 	d.SetName("TESTME")
@@ -181,9 +181,12 @@ func (d *BMP180Driver) read(address byte, n int) ([]byte, error) {
 	}
 	buf := make([]byte, n)
 	bytesRead, err := d.connection.Read(buf)
-	if bytesRead != n || err != nil {
+	if err != nil {
 		return nil, err
 	}
+	if bytesRead != n {
+		return nil, ErrNotEnoughBytes
+	}
 	return buf, nil
 }
 
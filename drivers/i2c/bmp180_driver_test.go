@@ -41,7 +41,10 @@ func TestBMP180Driver(t *testing.T) {
 }
 
 func TestBMP180DriverStart(t *testing.T) {
-	bmp180, _ := initTestBMP180DriverWithStubbedAdaptor()
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return len(b), nil
+	}
 	gobottest.Assert(t, bmp180.Start(), nil)
 }
 
@@ -175,6 +178,19 @@ func TestBMP180DriverPressureWriteError(t *testing.T) {
 	gobottest.Assert(t, err, errors.New("write error"))
 }
 
+func TestBMP180DriverShortRead(t *testing.T) {
+	bmp180, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return len(b) - 1, nil
+	}
+	gobottest.Assert(t, bmp180.Start(), ErrNotEnoughBytes)
+
+	_, err := bmp180.Temperature()
+	gobottest.Assert(t, err, ErrNotEnoughBytes)
+	_, err = bmp180.Pressure()
+	gobottest.Assert(t, err, ErrNotEnoughBytes)
+}
+
 func TestBMP180DriverSetName(t *testing.T) {
 	b := initTestBMP180Driver()
 	b.SetName("TESTME")
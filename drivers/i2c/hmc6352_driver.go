@@ -1,6 +1,10 @@
 package i2c
 
-import "gobot.io/x/gobot"
+import (
+	"time"
+
+	"gobot.io/x/gobot"
+)
 
 const hmc6352Address = 0x21
 
@@ -10,6 +14,7 @@ type HMC6352Driver struct {
 	connector  Connector
 	connection Connection
 	Config
+	gobot.Commander
 }
 
 // NewHMC6352Driver creates a new driver with specified i2c interface
@@ -20,17 +25,25 @@ type HMC6352Driver struct {
 //		i2c.WithBus(int):	bus to use with this driver
 //		i2c.WithAddress(int):	address to use with this driver
 //
+// Adds the following API Commands:
+//	"Heading" - See HMC6352Driver.Heading
 func NewHMC6352Driver(a Connector, options ...func(Config)) *HMC6352Driver {
 	hmc := &HMC6352Driver{
 		name:      gobot.DefaultName("HMC6352"),
 		connector: a,
 		Config:    NewConfig(),
+		Commander: gobot.NewCommander(),
 	}
 
 	for _, option := range options {
 		option(hmc)
 	}
 
+	hmc.AddCommand("Heading", func(params map[string]interface{}) interface{} {
+		heading, err := hmc.Heading()
+		return map[string]interface{}{"heading": heading, "err": err}
+	})
+
 	return hmc
 }
 
@@ -62,18 +75,21 @@ func (h *HMC6352Driver) Start() (err error) {
 // Halt returns true if devices is halted successfully
 func (h *HMC6352Driver) Halt() (err error) { return }
 
-// Heading returns the current heading
-func (h *HMC6352Driver) Heading() (heading uint16, err error) {
+// Heading returns the current heading in degrees, with a resolution of a
+// tenth of a degree
+func (h *HMC6352Driver) Heading() (heading float64, err error) {
 	if _, err = h.connection.Write([]byte("A")); err != nil {
 		return
 	}
+	// the compass needs 6ms to take the measurement
+	time.Sleep(6 * time.Millisecond)
 	buf := []byte{0, 0}
 	bytesRead, err := h.connection.Read(buf)
 	if err != nil {
 		return
 	}
 	if bytesRead == 2 {
-		heading = (uint16(buf[1]) + uint16(buf[0])*256) / 10
+		heading = float64(uint16(buf[1])+uint16(buf[0])*256) / 10
 		return
 	}
 
@@ -72,7 +72,7 @@ func TestHMC6352DriverHeading(t *testing.T) {
 	}
 
 	heading, _ := hmc.Heading()
-	gobottest.Assert(t, heading, uint16(2534))
+	gobottest.Assert(t, heading, 2534.5)
 
 	// when len(data) is not 2
 	hmc, adaptor = initTestHMC6352DriverWithStubbedAdaptor()
@@ -85,7 +85,7 @@ func TestHMC6352DriverHeading(t *testing.T) {
 	}
 
 	heading, err := hmc.Heading()
-	gobottest.Assert(t, heading, uint16(0))
+	gobottest.Assert(t, heading, 0.0)
 	gobottest.Assert(t, err, ErrNotEnoughBytes)
 
 	// when read error
@@ -98,7 +98,7 @@ func TestHMC6352DriverHeading(t *testing.T) {
 	}
 
 	heading, err = hmc.Heading()
-	gobottest.Assert(t, heading, uint16(0))
+	gobottest.Assert(t, heading, 0.0)
 	gobottest.Assert(t, err, errors.New("read error"))
 
 	// when write error
@@ -111,10 +111,25 @@ func TestHMC6352DriverHeading(t *testing.T) {
 	}
 
 	heading, err = hmc.Heading()
-	gobottest.Assert(t, heading, uint16(0))
+	gobottest.Assert(t, heading, 0.0)
 	gobottest.Assert(t, err, errors.New("write error"))
 }
 
+func TestHMC6352DriverHeadingCommand(t *testing.T) {
+	hmc, adaptor := initTestHMC6352DriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, hmc.Start(), nil)
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{0x0d, 0xe4})
+		return 2, nil
+	}
+
+	result := hmc.Command("Heading")(nil).(map[string]interface{})
+	gobottest.Assert(t, result["heading"], 355.6)
+	gobottest.Assert(t, result["err"], nil)
+}
+
 func TestHMC6352DriverSetName(t *testing.T) {
 	d := initTestHMC6352Driver()
 	d.SetName("TESTME")
@@ -9,6 +9,8 @@ import (
 const (
 	// Error event
 	Error = "error"
+	// Data event
+	Data = "data"
 )
 
 const (
@@ -78,6 +78,7 @@ type JHD1313M1Driver struct {
 	lcdConnection Connection
 	rgbAddress    int
 	rgbConnection Connection
+	gobot.Commander
 }
 
 // NewJHD1313M1Driver creates a new driver with specified i2c interface.
@@ -87,6 +88,13 @@ type JHD1313M1Driver struct {
 // Optional params:
 //		i2c.WithBus(int):	bus to use with this driver
 //
+// Adds the following API Commands:
+//	"SetRGB" - See JHD1313M1Driver.SetRGB
+//	"Clear" - See JHD1313M1Driver.Clear
+//	"Home" - See JHD1313M1Driver.Home
+//	"Write" - See JHD1313M1Driver.Write, writes on the given "line" (0 or 1) if passed
+//	"SetPosition" - See JHD1313M1Driver.SetPosition
+//	"Scroll" - See JHD1313M1Driver.Scroll
 func NewJHD1313M1Driver(a Connector, options ...func(Config)) *JHD1313M1Driver {
 	j := &JHD1313M1Driver{
 		name:       gobot.DefaultName("JHD1313M1"),
@@ -94,12 +102,43 @@ func NewJHD1313M1Driver(a Connector, options ...func(Config)) *JHD1313M1Driver {
 		Config:     NewConfig(),
 		lcdAddress: 0x3E,
 		rgbAddress: 0x62,
+		Commander:  gobot.NewCommander(),
 	}
 
 	for _, option := range options {
 		option(j)
 	}
 
+	j.AddCommand("SetRGB", func(params map[string]interface{}) interface{} {
+		r, _ := params["r"].(float64)
+		g, _ := params["g"].(float64)
+		b, _ := params["b"].(float64)
+		return j.SetRGB(int(r), int(g), int(b))
+	})
+	j.AddCommand("Clear", func(params map[string]interface{}) interface{} {
+		return j.Clear()
+	})
+	j.AddCommand("Home", func(params map[string]interface{}) interface{} {
+		return j.Home()
+	})
+	j.AddCommand("Write", func(params map[string]interface{}) interface{} {
+		msg, _ := params["msg"].(string)
+		if line, ok := params["line"].(float64); ok {
+			if err := j.SetPosition(int(line) * 16); err != nil {
+				return err
+			}
+		}
+		return j.Write(msg)
+	})
+	j.AddCommand("SetPosition", func(params map[string]interface{}) interface{} {
+		pos, _ := params["pos"].(float64)
+		return j.SetPosition(int(pos))
+	})
+	j.AddCommand("Scroll", func(params map[string]interface{}) interface{} {
+		lr, _ := params["lr"].(bool)
+		return j.Scroll(lr)
+	})
+
 	return j
 }
 
@@ -41,6 +41,25 @@ func TestJHD1313M1Driver(t *testing.T) {
 	gobottest.Assert(t, strings.HasPrefix(jhd.Name(), "JHD1313M1"), true)
 }
 
+func TestJHD1313MDriverCommands(t *testing.T) {
+	d, a := initTestJHD1313M1DriverWithStubbedAdaptor()
+	d.Start()
+
+	a.written = []byte{}
+	gobottest.Assert(t, d.Command("SetRGB")(map[string]interface{}{"r": 1.0, "g": 2.0, "b": 3.0}), nil)
+	gobottest.Assert(t, a.written, []byte{REG_RED, 1, REG_GREEN, 2, REG_BLUE, 3})
+
+	a.written = []byte{}
+	gobottest.Assert(t, d.Command("Write")(map[string]interface{}{"line": 1.0, "msg": "hi"}), nil)
+	gobottest.Assert(t, a.written, []byte{LCD_CMD, LCD_SETDDRAMADDR | LCD_2NDLINEOFFSET, LCD_DATA, 'h', LCD_DATA, 'i'})
+
+	gobottest.Assert(t, d.Command("Write")(map[string]interface{}{"line": 2.0, "msg": "hi"}), ErrInvalidPosition)
+	gobottest.Assert(t, d.Command("SetPosition")(map[string]interface{}{"pos": 40.0}), ErrInvalidPosition)
+	gobottest.Assert(t, d.Command("Clear")(nil), nil)
+	gobottest.Assert(t, d.Command("Home")(nil), nil)
+	gobottest.Assert(t, d.Command("Scroll")(map[string]interface{}{"lr": true}), nil)
+}
+
 func TestJHD1313MDriverSetName(t *testing.T) {
 	d := initTestJHD1313M1Driver()
 	d.SetName("TESTME")
@@ -1,11 +1,17 @@
 package i2c
 
 import (
+	"time"
+
 	"gobot.io/x/gobot"
 )
 
 const mma7660Address = 0x4c
 
+// mma7660Retries is how many times the axes are read again while the alert
+// bit tells that a register was being updated
+const mma7660Retries = 3
+
 const (
 	MMA7660_X              = 0x00
 	MMA7660_Y              = 0x01
@@ -30,11 +36,19 @@ const (
 	MMA7660_PD             = 0x0A
 )
 
+// MMA7660Acceleration is the acceleration in g published with the Data event
+type MMA7660Acceleration struct {
+	X, Y, Z float64
+}
+
 type MMA7660Driver struct {
 	name       string
 	connector  Connector
 	connection Connection
+	interval   time.Duration
+	halt       chan bool
 	Config
+	gobot.Eventer
 }
 
 // NewMMA7660Driver creates a new driver with specified i2c interface
@@ -44,22 +58,42 @@ type MMA7660Driver struct {
 // Optional params:
 //		i2c.WithBus(int):	bus to use with this driver
 //		i2c.WithAddress(int):	address to use with this driver
+//		i2c.WithMMA7660Interval(time.Duration):	interval of the Data event, 100ms by default
 //
+// Adds the following events:
+//		"data" - MMA7660Acceleration read every interval
+//		"error" - error met while reading the acceleration
 func NewMMA7660Driver(a Connector, options ...func(Config)) *MMA7660Driver {
 	m := &MMA7660Driver{
 		name:      gobot.DefaultName("MMA7660"),
 		connector: a,
 		Config:    NewConfig(),
+		Eventer:   gobot.NewEventer(),
+		interval:  100 * time.Millisecond,
 	}
 
 	for _, option := range options {
 		option(m)
 	}
 
+	m.AddEvent(Data)
+	m.AddEvent(Error)
+
 	// TODO: add commands for API
 	return m
 }
 
+// WithMMA7660Interval option sets the interval at which the MMA7660Driver
+// publishes the Data event, 0 disables it
+func WithMMA7660Interval(interval time.Duration) func(Config) {
+	return func(c Config) {
+		d, ok := c.(*MMA7660Driver)
+		if ok {
+			d.interval = interval
+		}
+	}
+}
+
 // Name returns the Name for the Driver
 func (h *MMA7660Driver) Name() string { return h.name }
 
@@ -91,35 +125,85 @@ func (h *MMA7660Driver) Start() (err error) {
 		return err
 	}
 
+	if h.interval > 0 {
+		h.halt = make(chan bool)
+		go h.poll(h.halt)
+	}
 	return
 }
 
-// Halt returns true if devices is halted successfully
-func (h *MMA7660Driver) Halt() (err error) { return }
+// Halt stops the Data event
+func (h *MMA7660Driver) Halt() (err error) {
+	if h.halt != nil {
+		close(h.halt)
+		h.halt = nil
+	}
+	return
+}
+
+// poll publishes the acceleration every interval until halt is closed
+func (h *MMA7660Driver) poll(halt chan bool) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			x, y, z, err := h.ReadAcceleration()
+			if err != nil {
+				h.Publish(h.Event(Error), err)
+				continue
+			}
+			h.Publish(h.Event(Data), MMA7660Acceleration{X: x, Y: y, Z: z})
+		case <-halt:
+			return
+		}
+	}
+}
+
+// ReadAcceleration reads the x, y and z axes and returns their acceleration
+// in g.
+func (h *MMA7660Driver) ReadAcceleration() (x, y, z float64, err error) {
+	if x, y, z, err = h.XYZ(); err != nil {
+		return
+	}
+	x, y, z = h.Acceleration(x, y, z)
+	return
+}
 
 // Acceleration returns the acceleration of the provided x, y, z
 func (h *MMA7660Driver) Acceleration(x, y, z float64) (ax, ay, az float64) {
 	return x / 21.0, y / 21.0, z / 21.0
 }
 
-// XYZ returns the raw x,y and z axis from the mma7660
+// XYZ returns the raw x,y and z axis from the mma7660. The axes are read
+// again while the alert bit of one of them tells it was being updated,
+// ErrNotReady is returned if it is still set after a few reads.
 func (h *MMA7660Driver) XYZ() (x float64, y float64, z float64, err error) {
 	buf := []byte{0, 0, 0}
-	bytesRead, err := h.connection.Read(buf)
-	if err != nil {
-		return
-	}
-
-	if bytesRead != 3 {
-		err = ErrNotEnoughBytes
-		return
-	}
+	for i := 0; i < mma7660Retries; i++ {
+		var bytesRead int
+		bytesRead, err = h.connection.Read(buf)
+		if err != nil {
+			return
+		}
 
-	for _, val := range buf {
-		if ((val >> 6) & 0x01) == 1 {
-			err = ErrNotReady
+		if bytesRead != 3 {
+			err = ErrNotEnoughBytes
 			return
 		}
+
+		err = nil
+		for _, val := range buf {
+			if ((val >> 6) & 0x01) == 1 {
+				err = ErrNotReady
+			}
+		}
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return
 	}
 
 	x = float64((int8(buf[0]) << 2)) / 4.0
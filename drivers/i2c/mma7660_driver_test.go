@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"gobot.io/x/gobot"
 	"gobot.io/x/gobot/gobottest"
@@ -137,3 +138,72 @@ func TestMMA7660DriverXYZNotReady(t *testing.T) {
 	_, _, _, err := d.XYZ()
 	gobottest.Assert(t, err, ErrNotReady)
 }
+
+func TestMMA7660DriverXYZAlert(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	d := NewMMA7660Driver(adaptor, WithMMA7660Interval(0))
+	d.Start()
+
+	// the y axis is being updated on the first read
+	reads := 0
+	adaptor.Testi2cReadImpl(func(b []byte) (int, error) {
+		reads++
+		if reads == 1 {
+			copy(b, []byte{0x11, 0x52, 0x13})
+		} else {
+			copy(b, []byte{0x11, 0x12, 0x13})
+		}
+		return 3, nil
+	})
+
+	x, y, z, err := d.XYZ()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, []float64{x, y, z}, []float64{17, 18, 19})
+	gobottest.Assert(t, reads, 2)
+}
+
+func TestMMA7660DriverReadAcceleration(t *testing.T) {
+	d, adaptor := initTestMMA7660DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		// 21, -21 and 0 counts
+		copy(b, []byte{0x15, 0x2B, 0x00})
+		return 3, nil
+	}
+	d.Start()
+	defer d.Halt()
+
+	x, y, z, err := d.ReadAcceleration()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, []float64{x, y, z}, []float64{1, -1, 0})
+}
+
+func TestMMA7660DriverDataEvent(t *testing.T) {
+	adaptor := newI2cTestAdaptor()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{0x15, 0x00, 0x2B})
+		return 3, nil
+	}
+	d := NewMMA7660Driver(adaptor, WithMMA7660Interval(time.Millisecond))
+
+	data := make(chan MMA7660Acceleration, 1)
+	d.Once(Data, func(val interface{}) {
+		data <- val.(MMA7660Acceleration)
+	})
+	gobottest.Assert(t, d.Start(), nil)
+
+	select {
+	case a := <-data:
+		gobottest.Assert(t, a, MMA7660Acceleration{X: 1, Y: 0, Z: -1})
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("data was not published")
+	}
+	gobottest.Assert(t, d.Halt(), nil)
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestMMA7660DriverNoInterval(t *testing.T) {
+	d := NewMMA7660Driver(newI2cTestAdaptor(), WithMMA7660Interval(0))
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, d.halt == nil, true)
+	gobottest.Assert(t, d.Halt(), nil)
+}
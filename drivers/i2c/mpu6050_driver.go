@@ -26,6 +26,17 @@ const MPU6050_ACCEL_FS_2 = 0x00
 const MPU6050_PWR1_SLEEP_BIT = 6
 const MPU6050_PWR1_ENABLE_BIT = 0
 
+const (
+	// Accelerometer event with the ThreeDData read by GetData
+	Accelerometer = "accelerometer"
+
+	// Gyroscope event with the ThreeDData read by GetData
+	Gyroscope = "gyroscope"
+
+	// Temperature event with the temperature in degrees Celsius read by GetData
+	Temperature = "temperature"
+)
+
 type ThreeDData struct {
 	X int16
 	Y int16
@@ -66,6 +77,10 @@ func NewMPU6050Driver(a Connector, options ...func(Config)) *MPU6050Driver {
 		option(m)
 	}
 
+	m.AddEvent(Accelerometer)
+	m.AddEvent(Gyroscope)
+	m.AddEvent(Temperature)
+
 	// TODO: add commands to API
 	return m
 }
@@ -92,6 +107,11 @@ func (h *MPU6050Driver) Start() (err error) {
 func (h *MPU6050Driver) Halt() (err error) { return }
 
 // GetData fetches the latest data from the MPU6050
+//
+// Emits the Events:
+//	Accelerometer ThreeDData - the accelerometer reading
+//	Gyroscope ThreeDData - the gyroscope reading
+//	Temperature int16 - the temperature in degrees Celsius
 func (h *MPU6050Driver) GetData() (err error) {
 	if _, err = h.connection.Write([]byte{MPU6050_RA_ACCEL_XOUT_H}); err != nil {
 		return
@@ -108,6 +128,10 @@ func (h *MPU6050Driver) GetData() (err error) {
 	binary.Read(buf, binary.BigEndian, &h.Temperature)
 	binary.Read(buf, binary.BigEndian, &h.Gyroscope)
 	h.convertToCelsius()
+
+	h.Publish(Accelerometer, h.Accelerometer)
+	h.Publish(Gyroscope, h.Gyroscope)
+	h.Publish(Temperature, h.Temperature)
 	return
 }
 
@@ -144,10 +168,8 @@ func (h *MPU6050Driver) initialize() (err error) {
 		return
 	}
 
-	// setSleepEnabled
-	if _, err = h.connection.Write([]byte{MPU6050_RA_PWR_MGMT_1,
-		MPU6050_PWR1_ENABLE_BIT,
-		0}); err != nil {
+	// wake up the device, it starts in sleep mode
+	if _, err = h.connection.Write([]byte{MPU6050_RA_PWR_MGMT_1, 0x00}); err != nil {
 		return
 	}
 
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"gobot.io/x/gobot"
 	"gobot.io/x/gobot/gobottest"
@@ -84,6 +85,47 @@ func TestMPU6050DriverReadData(t *testing.T) {
 	gobottest.Assert(t, mpu.Temperature, int16(36))
 }
 
+func TestMPU6050DriverGetDataEvents(t *testing.T) {
+	mpu, adaptor := initTestMPU6050DriverWithStubbedAdaptor()
+	gobottest.Assert(t, mpu.Start(), nil)
+
+	gobottest.Assert(t, adaptor.written[len(adaptor.written)-2:], []byte{0x6B, 0x00})
+
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{
+			0x00, 0x01, 0x00, 0x02, 0xff, 0xfd,
+			0xd1, 0x84,
+			0x00, 0x04, 0x00, 0x05, 0xff, 0xfa,
+		})
+		return 14, nil
+	}
+
+	accel := make(chan interface{}, 1)
+	gyro := make(chan interface{}, 1)
+	temp := make(chan interface{}, 1)
+	mpu.Once(Accelerometer, func(data interface{}) { accel <- data })
+	mpu.Once(Gyroscope, func(data interface{}) { gyro <- data })
+	mpu.Once(Temperature, func(data interface{}) { temp <- data })
+
+	gobottest.Assert(t, mpu.GetData(), nil)
+
+	for _, c := range []struct {
+		events chan interface{}
+		data   interface{}
+	}{
+		{accel, ThreeDData{X: 1, Y: 2, Z: -3}},
+		{gyro, ThreeDData{X: 4, Y: 5, Z: -6}},
+		{temp, int16(1)},
+	} {
+		select {
+		case data := <-c.events:
+			gobottest.Assert(t, data, c.data)
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("MPU6050 event was not published")
+		}
+	}
+}
+
 func TestMPU6050DriverGetDataReadError(t *testing.T) {
 	mpu, adaptor := initTestMPU6050DriverWithStubbedAdaptor()
 	mpu.Start()
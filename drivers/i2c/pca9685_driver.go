@@ -1,6 +1,7 @@
 package i2c
 
 import (
+	"math"
 	"strconv"
 	"time"
 
@@ -102,25 +103,22 @@ func (p *PCA9685Driver) SetPWM(channel int, on uint16, off uint16) (err error) {
 	return
 }
 
-// SetPWMFreq sets the PWM frequency in Hz
+// SetPWMFreq sets the PWM frequency in Hz, from 24 to 1526. The prescaler
+// can only be changed while the oscillator is off, so the device is put to
+// sleep, the prescaler written, and the device restarted.
 func (p *PCA9685Driver) SetPWMFreq(freq float32) error {
-	freq *= 0.9
-
-	var prescalevel float32 = 25000000
-	prescalevel /= 4096
-	prescalevel /= freq
-	prescalevel -= 1
-	prescale := byte(prescalevel + 0.5)
+	prescale := pca9685Prescale(freq)
 
 	if _, err := p.connection.Write([]byte{byte(PCA9685_MODE1)}); err != nil {
 		return err
 	}
 	data := make([]byte, 1)
-	oldmode, err := p.connection.Read(data)
-	if err != nil {
+	if _, err := p.connection.Read(data); err != nil {
 		return err
 	}
+	oldmode := data[0]
 
+	// sleep
 	newmode := (oldmode & 0x7F) | 0x10
 	if _, err := p.connection.Write([]byte{byte(PCA9685_MODE1), byte(newmode)}); err != nil {
 		return err
@@ -130,6 +128,7 @@ func (p *PCA9685Driver) SetPWMFreq(freq float32) error {
 		return err
 	}
 
+	// wake up, the oscillator needs 500us to settle before restarting
 	if _, err := p.connection.Write([]byte{byte(PCA9685_MODE1), byte(oldmode)}); err != nil {
 		return err
 	}
@@ -142,6 +141,19 @@ func (p *PCA9685Driver) SetPWMFreq(freq float32) error {
 	return nil
 }
 
+// pca9685Prescale returns the PRE_SCALE register value for freq from the
+// 25MHz internal clock, clamped to the 3-255 range the device accepts
+func pca9685Prescale(freq float32) byte {
+	prescale := math.Floor(25000000/(4096*float64(freq))+0.5) - 1
+	if prescale < 3 {
+		return 3
+	}
+	if prescale > 255 {
+		return 255
+	}
+	return byte(prescale)
+}
+
 // PwmWrite writes a PWM signal to the specified pin
 func (p *PCA9685Driver) PwmWrite(pin string, val byte) (err error) {
 	i, err := strconv.Atoi(pin)
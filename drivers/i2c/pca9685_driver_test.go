@@ -101,7 +101,25 @@ func TestPCA9685DriverSetPWMFreq(t *testing.T) {
 		copy(b, []byte{0x01})
 		return 1, nil
 	}
+	adaptor.written = []byte{}
 	gobottest.Assert(t, pca.SetPWMFreq(60), nil)
+	gobottest.Assert(t, adaptor.written, []byte{
+		PCA9685_MODE1,
+		PCA9685_MODE1, 0x11,
+		PCA9685_PRESCALE, 101,
+		PCA9685_MODE1, 0x01,
+		PCA9685_MODE1, 0xa1,
+	})
+}
+
+func TestPCA9685DriverPrescale(t *testing.T) {
+	gobottest.Assert(t, pca9685Prescale(50), byte(121))
+	gobottest.Assert(t, pca9685Prescale(60), byte(101))
+	gobottest.Assert(t, pca9685Prescale(1000), byte(5))
+	gobottest.Assert(t, pca9685Prescale(1526), byte(3))
+	gobottest.Assert(t, pca9685Prescale(5000), byte(3))
+	gobottest.Assert(t, pca9685Prescale(24), byte(253))
+	gobottest.Assert(t, pca9685Prescale(10), byte(255))
 }
 
 func TestPCA9685DriverSetPWMFreqReadError(t *testing.T) {
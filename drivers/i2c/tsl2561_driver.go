@@ -1,12 +1,18 @@
 package i2c
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"gobot.io/x/gobot"
 )
 
+// ErrTSL2561Saturated is the error returned by GetLux when a channel of the
+// sensor is saturated, such as in direct sunlight at a high gain or a long
+// integration time
+var ErrTSL2561Saturated = errors.New("TSL2561 sensor is saturated")
+
 const (
 	// TSL2561AddressLow - the address of the device when address pin is low
 	TSL2561AddressLow = 0x29
@@ -387,11 +393,11 @@ func (d *TSL2561Driver) CalculateLux(broadband uint16, ir uint16) (lux uint32) {
 	ratio := (ratio1 + 1) / 2
 
 	b, m := d.getBM(ratio)
-	temp := (channel0 * b) - (channel1 * m)
 
 	// Negative lux not allowed
-	if temp < 0 {
-		temp = 0
+	var temp uint32
+	if channel0*b > channel1*m {
+		temp = (channel0 * b) - (channel1 * m)
 	}
 
 	// Round lsb (2^(LUX_SCALE+1))
@@ -403,6 +409,22 @@ func (d *TSL2561Driver) CalculateLux(broadband uint16, ir uint16) (lux uint32) {
 	return lux
 }
 
+// GetLux reads the sensor, adjusting gain if auto-gain is enabled, and
+// returns the illuminance in lux for the configured gain and integration
+// time. ErrTSL2561Saturated is returned when the light is beyond the range
+// of the sensor.
+func (d *TSL2561Driver) GetLux() (lux float64, err error) {
+	broadband, ir, err := d.GetLuminocity()
+	if err != nil {
+		return
+	}
+
+	if clipThreshold, _ := d.getClipScaling(); broadband > clipThreshold || ir > clipThreshold {
+		return 0, ErrTSL2561Saturated
+	}
+	return float64(d.CalculateLux(broadband, ir)), nil
+}
+
 func (d *TSL2561Driver) enable() (err error) {
 	err = d.connection.WriteByteData(uint8(tsl2561CommandBit|tsl2561RegisterControl), tsl2561ControlPowerOn)
 	return err
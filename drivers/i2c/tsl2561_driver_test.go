@@ -221,6 +221,38 @@ func TestTSL2561DriverGetLuminocityAutoGain(t *testing.T) {
 	gobottest.Assert(t, d.CalculateLux(bb, ir), uint32(72))
 }
 
+func TestTSL2561DriverGetLux(t *testing.T) {
+	d, adaptor := initTestTSL2561Driver()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{77, 48})
+		return 2, nil
+	}
+
+	d.Start()
+	lux, err := d.GetLux()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, lux, 72.0)
+
+	// the 402ms clipping threshold is 65000
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		copy(b, []byte{0xFF, 0xFF})
+		return 2, nil
+	}
+	lux, err = d.GetLux()
+	gobottest.Assert(t, err, ErrTSL2561Saturated)
+	gobottest.Assert(t, lux, 0.0)
+}
+
+func TestTSL2561DriverGetLuxReadError(t *testing.T) {
+	d, adaptor := initTestTSL2561Driver()
+	d.Start()
+	adaptor.i2cReadImpl = func(b []byte) (int, error) {
+		return 0, errors.New("read error")
+	}
+	_, err := d.GetLux()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
 func TestTSL2561SetIntegrationTimeError(t *testing.T) {
 	d, adaptor := initTestTSL2561Driver()
 	d.Start()
@@ -1,10 +1,20 @@
 package gobot
 
-import "sync"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
 
 type eventChannel chan *Event
 
 type eventer struct {
+	// number of events dropped from full channels, first for 64-bit
+	// alignment of atomic operations
+	dropped uint64
+
 	// map of valid Event names
 	eventnames map[string]string
 
@@ -14,10 +24,19 @@ type eventer struct {
 	// map of out channels used by subscribers
 	outs map[eventChannel]eventChannel
 
+	// number of events buffered by each channel
+	bufferSize int
+
 	// mutex to protect the eventChannel map
 	eventsMutex sync.Mutex
+
+	// held while an event is cascaded, so that out channels are not closed
+	// while sending to them
+	cascadeMutex sync.Mutex
 }
 
+// eventChanBufferSize is the default number of events an eventer buffers
+// for the publishers and for each subscriber.
 const eventChanBufferSize = 10
 
 // Eventer is the interface which describes how a Driver or Adaptor
@@ -52,12 +71,61 @@ type Eventer interface {
 	Once(name string, f func(s interface{})) (err error)
 }
 
+// DropCounter is implemented by the Eventer returned by NewEventer, and by
+// any Eventer which drops events when its subscribers fall behind.
+type DropCounter interface {
+	// Dropped returns the number of events dropped because a buffer was full
+	Dropped() uint64
+}
+
+// Listener is implemented by the Eventer returned by NewEventer, and by any
+// Eventer whose event handlers can be removed.
+type Listener interface {
+	// Event handler which can be removed by cancelling the Subscription
+	Listen(name string, f func(s interface{})) *Subscription
+
+	// Event handler, only executes one time, which can be removed by
+	// cancelling the Subscription before the event is Published
+	ListenOnce(name string, f func(s interface{})) *Subscription
+}
+
+// Subscription is an event handler registered with Listen.
+type Subscription struct {
+	eventer *eventer
+	events  eventChannel
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Cancel removes the event handler, it is not executed for events Published
+// after Cancel returns.
+func (s *Subscription) Cancel() {
+	s.once.Do(func() {
+		close(s.done)
+		s.eventer.Unsubscribe(s.events)
+	})
+}
+
 // NewEventer returns a new Eventer.
-func NewEventer() Eventer {
+//
+// Optionally accepts:
+//
+//	int: number of events buffered for the publishers and for each
+//	subscriber, defaults to 10
+func NewEventer(args ...interface{}) Eventer {
+	bufferSize := eventChanBufferSize
+	for _, arg := range args {
+		switch arg.(type) {
+		case int:
+			bufferSize = arg.(int)
+		}
+	}
+
 	evtr := &eventer{
 		eventnames: make(map[string]string),
-		in:         make(eventChannel, eventChanBufferSize),
+		in:         make(eventChannel, bufferSize),
 		outs:       make(map[eventChannel]eventChannel),
+		bufferSize: bufferSize,
 	}
 
 	// goroutine to cascade "in" events to all "out" event channels
@@ -65,11 +133,18 @@ func NewEventer() Eventer {
 		for {
 			select {
 			case evt := <-evtr.in:
+				evtr.cascadeMutex.Lock()
 				evtr.eventsMutex.Lock()
+				outs := make([]eventChannel, 0, len(evtr.outs))
 				for _, out := range evtr.outs {
-					out <- evt
+					outs = append(outs, out)
 				}
 				evtr.eventsMutex.Unlock()
+
+				for _, out := range outs {
+					evtr.send(out, evt)
+				}
+				evtr.cascadeMutex.Unlock()
 			}
 		}
 	}()
@@ -98,58 +173,160 @@ func (e *eventer) DeleteEvent(name string) {
 	delete(e.eventnames, name)
 }
 
-// Publish new events to anyone that is subscribed
+// Publish new events to anyone that is subscribed. Publish does not wait
+// for slow subscribers: when the publishers' buffer or the buffer of a
+// subscriber is full, its oldest event is dropped to make room. Events are
+// delivered at most once, and under overload some are not delivered at all,
+// see Dropped.
 func (e *eventer) Publish(name string, data interface{}) {
 	evt := NewEvent(name, data)
-	e.in <- evt
+	e.send(e.in, evt)
+}
+
+// Dropped returns the number of events dropped because a buffer was full.
+func (e *eventer) Dropped() uint64 {
+	return atomic.LoadUint64(&e.dropped)
+}
+
+// send puts evt in to the channel, dropping the oldest events when it is full
+func (e *eventer) send(events eventChannel, evt *Event) {
+	for {
+		select {
+		case events <- evt:
+			return
+		default:
+		}
+
+		select {
+		case <-events:
+			atomic.AddUint64(&e.dropped, 1)
+		default:
+		}
+	}
 }
 
 // Subscribe to any events from this eventer
 func (e *eventer) Subscribe() eventChannel {
 	e.eventsMutex.Lock()
 	defer e.eventsMutex.Unlock()
-	out := make(eventChannel, eventChanBufferSize)
+	out := make(eventChannel, e.bufferSize)
 	e.outs[out] = out
 	return out
 }
 
-// Unsubscribe from the event channel
+// Unsubscribe from the event channel, which is then closed.
 func (e *eventer) Unsubscribe(events eventChannel) {
 	e.eventsMutex.Lock()
-	defer e.eventsMutex.Unlock()
+	_, ok := e.outs[events]
 	delete(e.outs, events)
+	e.eventsMutex.Unlock()
+	if !ok {
+		return
+	}
+
+	e.cascadeMutex.Lock()
+	defer e.cascadeMutex.Unlock()
+	close(events)
 }
 
 // On executes the event handler f when e is Published to.
 func (e *eventer) On(n string, f func(s interface{})) (err error) {
-	out := e.Subscribe()
+	e.Listen(n, f)
+	return
+}
+
+// Listen executes the event handler f when e is Published to, until the
+// returned Subscription is cancelled.
+func (e *eventer) Listen(n string, f func(s interface{})) *Subscription {
+	sub := e.subscription()
 	go func() {
-		for {
+		for evt := range sub.events {
 			select {
-			case evt := <-out:
-				if evt.Name == n {
-					f(evt.Data)
-				}
+			case <-sub.done:
+				return
+			default:
+			}
+			if evt.Name == n {
+				f(evt.Data)
 			}
 		}
 	}()
 
-	return
+	return sub
 }
 
-// Once is similar to On except that it only executes f one time.
-func (e *eventer) Once(n string, f func(s interface{})) (err error) {
-	out := e.Subscribe()
+// ListenOnce executes the event handler f the first time e is Published to.
+// Cancelling the returned Subscription before then removes f, along with
+// its goroutine, as when waiting for a reply which does not come.
+func (e *eventer) ListenOnce(n string, f func(s interface{})) *Subscription {
+	sub := e.subscription()
 	go func() {
-	ProcessEvents:
-		for evt := range out {
+		for evt := range sub.events {
+			select {
+			case <-sub.done:
+				return
+			default:
+			}
 			if evt.Name == n {
+				sub.Cancel()
 				f(evt.Data)
-				e.Unsubscribe(out)
-				break ProcessEvents
+				return
 			}
 		}
 	}()
 
+	return sub
+}
+
+func (e *eventer) subscription() *Subscription {
+	return &Subscription{
+		eventer: e,
+		events:  e.Subscribe(),
+		done:    make(chan struct{}),
+	}
+}
+
+// Once is similar to On except that it only executes f one time. The
+// handler is kept until the event is Published, use ListenOnce to be able
+// to remove it before.
+func (e *eventer) Once(n string, f func(s interface{})) (err error) {
+	e.ListenOnce(n, f)
 	return
 }
+
+// OnTyped executes the event handler f when e is Published to, like On,
+// but f takes the event data as its concrete type, e.g. func(data []byte).
+// Event data that does not match the type of the handler's parameter is not
+// passed to f, an error is published to the "error" event instead when e
+// has one.
+func OnTyped(e Eventer, n string, f interface{}) (err error) {
+	fn := reflect.ValueOf(f)
+	if fn.Kind() != reflect.Func || fn.Type().NumIn() != 1 {
+		return errors.New("Event handler must be a func with a single parameter")
+	}
+	typ := fn.Type().In(0)
+
+	return e.On(n, func(data interface{}) {
+		var arg reflect.Value
+		switch {
+		case data != nil && reflect.TypeOf(data).AssignableTo(typ):
+			arg = reflect.ValueOf(data)
+		case data == nil && isNillable(typ):
+			arg = reflect.Zero(typ)
+		default:
+			if e.Event("error") != "" {
+				e.Publish("error", fmt.Errorf("Event %s: expected %v data, got %T", n, typ, data))
+			}
+			return
+		}
+		fn.Call([]reflect.Value{arg})
+	})
+}
+
+func isNillable(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return true
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package gobot
 
 import (
+	"runtime"
 	"testing"
 	"time"
 
@@ -76,3 +77,251 @@ func TestEventerOnce(t *testing.T) {
 	case <-time.After(10 * time.Millisecond):
 	}
 }
+
+func TestEventerListenOnce(t *testing.T) {
+	e := NewEventer()
+	e.AddEvent("test")
+	before := runtime.NumGoroutine()
+
+	sem := make(chan bool, 2)
+	e.(Listener).ListenOnce("test", func(data interface{}) {
+		sem <- true
+	})
+	e.Publish("test", true)
+	e.Publish("test", true)
+
+	select {
+	case <-sem:
+	case <-time.After(10 * time.Millisecond):
+		t.Errorf("ListenOnce was not called")
+	}
+	select {
+	case <-sem:
+		t.Errorf("ListenOnce was called twice")
+	case <-time.After(10 * time.Millisecond):
+	}
+	gobottest.AssertMaxGoroutines(t, before)
+}
+
+func TestEventerListenOnceTimeoutsDoNotLeak(t *testing.T) {
+	e := NewEventer()
+	e.AddEvent("reply")
+	before := runtime.NumGoroutine()
+
+	// waiting for replies which never come, as a read timing out does
+	for i := 0; i < 1000; i++ {
+		replied := make(chan bool, 1)
+		sub := e.(Listener).ListenOnce("reply", func(data interface{}) {
+			replied <- true
+		})
+		select {
+		case <-replied:
+			t.Fatalf("unexpected reply")
+		case <-time.After(time.Microsecond):
+		}
+		sub.Cancel()
+	}
+	gobottest.AssertMaxGoroutines(t, before)
+}
+
+func TestEventerUnsubscribeFullChannel(t *testing.T) {
+	e := NewEventer()
+	e.AddEvent("test")
+	events := e.Subscribe()
+
+	// more events than the channel holds, nobody reading
+	published := make(chan bool)
+	go func() {
+		for i := 0; i < 3*eventChanBufferSize; i++ {
+			e.Publish("test", i)
+		}
+		published <- true
+	}()
+	for len(events) < cap(events) {
+		time.Sleep(time.Millisecond)
+	}
+
+	unsubscribed := make(chan bool)
+	go func() {
+		e.Unsubscribe(events)
+		e.Unsubscribe(events)
+		unsubscribed <- true
+	}()
+
+	select {
+	case <-unsubscribed:
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("Unsubscribe blocked")
+	}
+	select {
+	case <-published:
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("Publish blocked")
+	}
+
+	_, open := <-events
+	for open {
+		_, open = <-events
+	}
+}
+
+func TestEventerUnsubscribeUnknownChannel(t *testing.T) {
+	e := NewEventer()
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		e.Unsubscribe(make(eventChannel))
+	}
+	gobottest.AssertMaxGoroutines(t, before)
+}
+
+func TestEventerOnUnsubscribe(t *testing.T) {
+	e := NewEventer().(*eventer)
+	e.AddEvent("test")
+	before := runtime.NumGoroutine()
+	e.On("test", func(data interface{}) {})
+
+	e.eventsMutex.Lock()
+	var out eventChannel
+	for out = range e.outs {
+	}
+	e.eventsMutex.Unlock()
+
+	// the handler goroutine exits once its channel is closed
+	e.Unsubscribe(out)
+	gobottest.AssertMaxGoroutines(t, before)
+}
+
+func TestEventerOnTyped(t *testing.T) {
+	e := NewEventer()
+	e.AddEvent("test")
+	e.AddEvent("error")
+
+	sem := make(chan []byte)
+	gobottest.Assert(t, OnTyped(e, "test", func(data []byte) {
+		sem <- data
+	}), nil)
+	errs := make(chan error)
+	OnTyped(e, "error", func(err error) {
+		errs <- err
+	})
+
+	e.Publish("test", []byte{1, 2})
+	select {
+	case data := <-sem:
+		gobottest.Assert(t, data, []byte{1, 2})
+	case <-time.After(10 * time.Millisecond):
+		t.Errorf("OnTyped was not called")
+	}
+
+	e.Publish("test", "wrong")
+	select {
+	case <-sem:
+		t.Errorf("OnTyped was called with mismatched data")
+	case err := <-errs:
+		gobottest.Assert(t, err.Error(), "Event test: expected []uint8 data, got string")
+	case <-time.After(10 * time.Millisecond):
+		t.Errorf("Mismatched data was not reported")
+	}
+
+	e.Publish("test", nil)
+	select {
+	case data := <-sem:
+		gobottest.Assert(t, data == nil, true)
+	case <-time.After(10 * time.Millisecond):
+		t.Errorf("OnTyped was not called")
+	}
+}
+
+func TestEventerOnTypedInvalidHandler(t *testing.T) {
+	e := NewEventer()
+	gobottest.Assert(t, OnTyped(e, "test", "handler").Error(),
+		"Event handler must be a func with a single parameter")
+	gobottest.Refute(t, OnTyped(e, "test", func(a, b int) {}), nil)
+}
+
+func TestEventerListenCancel(t *testing.T) {
+	e := NewEventer()
+	e.AddEvent("test")
+	before := runtime.NumGoroutine()
+
+	sem := make(chan bool, 1)
+	sub := e.(Listener).Listen("test", func(data interface{}) {
+		sem <- true
+	})
+
+	e.Publish("test", true)
+	select {
+	case <-sem:
+	case <-time.After(10 * time.Millisecond):
+		t.Errorf("Listen was not called")
+	}
+
+	sub.Cancel()
+	sub.Cancel()
+	e.Publish("test", true)
+	select {
+	case <-sem:
+		t.Errorf("Listen was called after Cancel")
+	case <-time.After(10 * time.Millisecond):
+	}
+	gobottest.AssertMaxGoroutines(t, before)
+}
+
+func TestEventerListenCancelDuringPublish(t *testing.T) {
+	e := NewEventer()
+	e.AddEvent("test")
+
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 1000; i++ {
+			e.Publish("test", i)
+		}
+		done <- true
+	}()
+
+	for i := 0; i < 100; i++ {
+		sub := e.(Listener).Listen("test", func(data interface{}) {})
+		sub.Cancel()
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("Publish blocked")
+	}
+}
+
+func TestEventerBufferSize(t *testing.T) {
+	e := NewEventer(3)
+	events := e.Subscribe()
+	gobottest.Assert(t, cap(events), 3)
+	gobottest.Assert(t, cap(NewEventer().Subscribe()), eventChanBufferSize)
+}
+
+func TestEventerPublishDropsOldest(t *testing.T) {
+	e := NewEventer(2)
+	e.AddEvent("test")
+	events := e.Subscribe()
+
+	// nobody reading, Publish must not block
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			e.Publish("test", i)
+		}
+		done <- true
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("Publish blocked")
+	}
+
+	// the newest events are kept
+	for e.(DropCounter).Dropped() < 98 {
+		time.Sleep(time.Millisecond)
+	}
+	gobottest.Assert(t, e.(DropCounter).Dropped(), uint64(98))
+	gobottest.Assert(t, (<-events).Data, 98)
+	gobottest.Assert(t, (<-events).Data, 99)
+}
@@ -19,11 +19,11 @@ func main() {
 	work := func() {
 		spheroDriver.SetDataStreaming(sphero.DefaultDataStreamingConfig())
 
-		spheroDriver.On(sphero.Collision, func(data interface{}) {
+		gobot.OnTyped(spheroDriver, sphero.Collision, func(data sphero.CollisionPacket) {
 			fmt.Printf("Collision! %+v\n", data)
 		})
 
-		spheroDriver.On(sphero.SensorData, func(data interface{}) {
+		gobot.OnTyped(spheroDriver, sphero.SensorData, func(data sphero.DataStreamingPacket) {
 			fmt.Printf("Streaming Data! %+v\n", data)
 		})
 
@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 var errFunc = func(t *testing.T, message string) {
@@ -36,6 +37,18 @@ func Refute(t *testing.T, a interface{}, b interface{}) {
 	}
 }
 
+// AssertMaxGoroutines waits up to a second for the number of goroutines to
+// drop to n, emits a t.Errorf if more goroutines are still running.
+func AssertMaxGoroutines(t *testing.T, n int) {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if running := runtime.NumGoroutine(); running > n {
+		logFailure(t, fmt.Sprintf("%v goroutines running, should be at most %v", running, n))
+	}
+}
+
 func ExecCommand(command string, args ...string) *exec.Cmd {
 	cs := []string{"-test.run=TestHelperProcess", "--", command}
 	cs = append(cs, args...)
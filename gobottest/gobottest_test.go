@@ -36,6 +36,23 @@ func TestRefute(t *testing.T) {
 	}
 }
 
+func TestAssertMaxGoroutines(t *testing.T) {
+	err := ""
+	errFunc = func(t *testing.T, message string) {
+		err = message
+	}
+
+	AssertMaxGoroutines(t, 1000)
+	if err != "" {
+		t.Errorf("AssertMaxGoroutines failed: few goroutines are running")
+	}
+
+	AssertMaxGoroutines(t, 0)
+	if err == "" {
+		t.Errorf("AssertMaxGoroutines failed: the test itself runs in a goroutine")
+	}
+}
+
 func TestExecCommand(t *testing.T) {
 	val := ExecCommand("echo", "hello")
 	Refute(t, val, nil)
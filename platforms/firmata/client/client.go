@@ -6,7 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -21,6 +21,8 @@ const (
 	Analog = 0x02
 	Pwm    = 0x03
 	Servo  = 0x04
+	Pullup = 0x0B
+	Tone   = 0x0E
 )
 
 // Sysex Codes
@@ -44,6 +46,7 @@ const (
 	PinStateResponse         byte = 0x6E
 	AnalogMappingQuery       byte = 0x69
 	AnalogMappingResponse    byte = 0x6A
+	ExtendedAnalog           byte = 0x6F
 	StringData               byte = 0x71
 	I2CRequest               byte = 0x76
 	I2CReply                 byte = 0x77
@@ -53,9 +56,36 @@ const (
 	I2CModeRead              byte = 0x01
 	I2CModeContinuousRead    byte = 0x02
 	I2CModeStopReading       byte = 0x03
+	I2CRestartTransmission   byte = 0x40
 	ServoConfig              byte = 0x70
+	SamplingInterval         byte = 0x7A
+	ToneData                 byte = 0x5F
+	ToneDataTone             byte = 0x00
+	ToneDataNoTone           byte = 0x01
+	StepperData              byte = 0x72
+	StepperDataConfig        byte = 0x00
+	StepperDataStep          byte = 0x01
+	EncoderData              byte = 0x61
+	EncoderDataAttach        byte = 0x00
+	EncoderDataReset         byte = 0x03
+	EncoderDataReportAuto    byte = 0x04
+	EncoderDataDetach        byte = 0x05
 )
 
+// Stepper interfaces
+const (
+	// StepperDriver is a stepper driver board, with a direction and a step pin
+	StepperDriver = 0x01
+	// StepperTwoWire is a stepper wired to two pins
+	StepperTwoWire = 0x02
+
+	// MaxSteppers is the number of steppers the firmware can drive
+	MaxSteppers = 6
+)
+
+// MaxEncoders is the number of encoders the firmware can read
+const MaxEncoders = 5
+
 // Errors
 var (
 	ErrConnected = errors.New("client is already connected")
@@ -71,8 +101,14 @@ type Client struct {
 	connection      io.ReadWriteCloser
 	analogPins      []int
 	ConnectTimeout  time.Duration
-	initFunc        func() error
-	initMutex       sync.Mutex
+	// ResetOnConnect sends SystemReset before the handshake, to clear pin
+	// reporting and servos left by a previous session. It is enabled by
+	// default.
+	ResetOnConnect bool
+	initFunc       func() error
+	initMutex      sync.Mutex
+	writeMutex     sync.Mutex
+	pinsMutex      sync.Mutex
 	gobot.Eventer
 }
 
@@ -83,6 +119,22 @@ type Pin struct {
 	Value          int
 	State          int
 	AnalogChannel  int
+	// Resolutions is the resolution in bits of each supported mode, as
+	// reported by the capability query
+	Resolutions map[int]int
+}
+
+// Firmware represents the response from a FirmwareQuery message
+type Firmware struct {
+	Name  string
+	Major int
+	Minor int
+}
+
+// EncoderPosition represents a position reported in an EncoderData message
+type EncoderPosition struct {
+	Encoder  int
+	Position int
 }
 
 // I2cReply represents the response from an I2cReply message
@@ -99,6 +151,7 @@ func New() *Client {
 		FirmwareName:    "",
 		connection:      nil,
 		ConnectTimeout:  15 * time.Second,
+		ResetOnConnect:  true,
 		pins:            []Pin{},
 		analogPins:      []int{},
 		Eventer:         gobot.NewEventer(),
@@ -109,11 +162,14 @@ func New() *Client {
 
 	for _, s := range []string{
 		"FirmwareQuery",
+		"Firmware",
 		"CapabilityQuery",
 		"AnalogMappingQuery",
 		"ProtocolVersion",
 		"I2cReply",
 		"StringData",
+		"StepperDone",
+		"EncoderPosition",
 		"Error",
 	} {
 		c.AddEvent(s)
@@ -151,50 +207,68 @@ func (b *Client) Pins() []Pin {
 	return b.pins
 }
 
-// Connect connects to the Client given conn. It first resets the firmata board
-// then continuously polls the firmata board for new information when it's
-// available.
+// once registers f for the next name event, like Once, and returns a func
+// removing it when the event does not come.
+func (b *Client) once(name string, f func(data interface{})) (cancel func()) {
+	if l, ok := b.Eventer.(gobot.Listener); ok {
+		return l.ListenOnce(name, f).Cancel
+	}
+	b.Once(name, f)
+	return func() {}
+}
+
+// Connect connects to the Client given conn. It first resets the firmata board,
+// unless ResetOnConnect is disabled, then continuously polls the firmata board
+// for new information when it's available.
 func (b *Client) Connect(conn io.ReadWriteCloser) (err error) {
 	if b.Connected() {
 		return ErrConnected
 	}
 
 	b.connection = conn
-	b.Reset()
+	if b.ResetOnConnect {
+		if err = b.Reset(); err != nil {
+			return err
+		}
+	}
 	connected := make(chan bool, 1)
 	connectError := make(chan error, 1)
 
-	b.Once(b.Event("ProtocolVersion"), func(data interface{}) {
+	cancelProtocolVersion := b.once(b.Event("ProtocolVersion"), func(data interface{}) {
 		e := b.FirmwareQuery()
 		if e != nil {
 			b.setConnecting(false)
 			connectError <- e
 		}
 	})
+	defer cancelProtocolVersion()
 
-	b.Once(b.Event("FirmwareQuery"), func(data interface{}) {
+	cancelFirmware := b.once(b.Event("FirmwareQuery"), func(data interface{}) {
 		e := b.CapabilitiesQuery()
 		if e != nil {
 			b.setConnecting(false)
 			connectError <- e
 		}
 	})
+	defer cancelFirmware()
 
-	b.Once(b.Event("CapabilityQuery"), func(data interface{}) {
+	cancelCapabilities := b.once(b.Event("CapabilityQuery"), func(data interface{}) {
 		e := b.AnalogMappingQuery()
 		if e != nil {
 			b.setConnecting(false)
 			connectError <- e
 		}
 	})
+	defer cancelCapabilities()
 
-	b.Once(b.Event("AnalogMappingQuery"), func(data interface{}) {
+	cancelAnalogMapping := b.once(b.Event("AnalogMappingQuery"), func(data interface{}) {
 		b.ReportDigital(0, 1)
 		b.ReportDigital(1, 1)
 		b.setConnecting(false)
 		b.setConnected(true)
 		connected <- true
 	})
+	defer cancelAnalogMapping()
 
 	// start it off...
 	b.setConnecting(true)
@@ -218,6 +292,7 @@ func (b *Client) Connect(conn io.ReadWriteCloser) (err error) {
 	case e := <-connectError:
 		return e
 	case <-time.After(b.ConnectTimeout):
+		b.setConnecting(false)
 		return errors.New("unable to connect. Perhaps you need to flash your Arduino with Firmata?")
 	}
 
@@ -229,6 +304,10 @@ func (b *Client) Connect(conn io.ReadWriteCloser) (err error) {
 
 			if err := b.process(); err != nil {
 				b.Publish(b.Event("Error"), err)
+				// the connection was closed, by the board or by Disconnect
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					break
+				}
 			}
 		}
 	}()
@@ -249,17 +328,37 @@ func (b *Client) SetPinMode(pin int, mode int) error {
 
 // DigitalWrite writes value to pin.
 func (b *Client) DigitalWrite(pin int, value int) error {
-	port := byte(math.Floor(float64(pin) / 8))
-	portValue := byte(0)
-
-	b.pins[pin].Value = value
+	return b.DigitalWritePins(map[int]int{pin: value})
+}
 
-	for i := byte(0); i < 8; i++ {
-		if b.pins[8*port+i].Value != 0 {
-			portValue = portValue | (1 << i)
+// DigitalWritePins writes the values of several pins, keyed by pin number,
+// with one DIGITAL_MESSAGE per port. The messages are sent in one write.
+func (b *Client) DigitalWritePins(values map[int]int) error {
+	b.pinsMutex.Lock()
+	dirty := map[int]bool{}
+	for pin, value := range values {
+		b.pins[pin].Value = value
+		dirty[pin/8] = true
+	}
+	ports := []int{}
+	for port := range dirty {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+
+	ret := []byte{}
+	for _, port := range ports {
+		portValue := byte(0)
+		for i := 0; i < 8 && 8*port+i < len(b.pins); i++ {
+			if b.pins[8*port+i].Value != 0 {
+				portValue = portValue | (1 << byte(i))
+			}
 		}
+		ret = append(ret, DigitalMessage|byte(port), portValue&0x7F, (portValue>>7)&0x7F)
 	}
-	return b.write([]byte{DigitalMessage | port, portValue & 0x7F, (portValue >> 7) & 0x7F})
+	b.pinsMutex.Unlock()
+
+	return b.write(ret)
 }
 
 // ServoConfig sets the min and max pulse width for servo PWM range
@@ -275,12 +374,104 @@ func (b *Client) ServoConfig(pin int, max int, min int) error {
 	return b.WriteSysex(ret)
 }
 
+// Tone plays a square wave of frequency Hz on pin for duration
+// milliseconds, or until NoTone when the duration is 0. It requires firmware
+// with the tone feature, such as ConfigurableFirmata.
+func (b *Client) Tone(pin int, frequency int, duration int) error {
+	return b.WriteSysex([]byte{
+		ToneData,
+		ToneDataTone,
+		byte(pin),
+		byte(frequency & 0x7F),
+		byte((frequency >> 7) & 0x7F),
+		byte(duration & 0x7F),
+		byte((duration >> 7) & 0x7F),
+	})
+}
+
+// NoTone stops the tone played on pin.
+func (b *Client) NoTone(pin int) error {
+	return b.WriteSysex([]byte{ToneData, ToneDataNoTone, byte(pin)})
+}
+
+// StepperConfig configures the stepper deviceNum, wired to dirPin and
+// stepPin through the stepper interface, one of StepperDriver or
+// StepperTwoWire.
+func (b *Client) StepperConfig(deviceNum int, stepInterface int, stepsPerRev int, dirPin int, stepPin int) error {
+	return b.WriteSysex([]byte{
+		StepperData,
+		StepperDataConfig,
+		byte(deviceNum),
+		byte(stepInterface),
+		byte(stepsPerRev & 0x7F),
+		byte((stepsPerRev >> 7) & 0x7F),
+		byte(dirPin),
+		byte(stepPin),
+	})
+}
+
+// StepperStep moves the stepper deviceNum by steps in the direction, 0 for
+// clockwise and 1 for counter clockwise, at speed in 0.01 rad/sec. The board
+// reports the end of the move, which is published as a StepperDone event
+// with the device number.
+func (b *Client) StepperStep(deviceNum int, direction int, steps int, speed int) error {
+	return b.WriteSysex([]byte{
+		StepperData,
+		StepperDataStep,
+		byte(deviceNum),
+		byte(direction),
+		byte(steps & 0x7F),
+		byte((steps >> 7) & 0x7F),
+		byte((steps >> 14) & 0x7F),
+		byte(speed & 0x7F),
+		byte((speed >> 7) & 0x7F),
+	})
+}
+
+// EncoderAttach attaches the encoder encoderNum, wired to pinA and pinB.
+func (b *Client) EncoderAttach(encoderNum int, pinA int, pinB int) error {
+	return b.WriteSysex([]byte{EncoderData, EncoderDataAttach, byte(encoderNum), byte(pinA), byte(pinB)})
+}
+
+// EncoderReset sets the position of the encoder encoderNum back to 0.
+func (b *Client) EncoderReset(encoderNum int) error {
+	return b.WriteSysex([]byte{EncoderData, EncoderDataReset, byte(encoderNum)})
+}
+
+// EncoderDetach detaches the encoder encoderNum.
+func (b *Client) EncoderDetach(encoderNum int) error {
+	return b.WriteSysex([]byte{EncoderData, EncoderDataDetach, byte(encoderNum)})
+}
+
+// EncoderReportAuto enables or disables the continuous report of the
+// positions of all attached encoders, each published as an EncoderPosition
+// event.
+func (b *Client) EncoderReportAuto(enable bool) error {
+	e := byte(0)
+	if enable {
+		e = 1
+	}
+	return b.WriteSysex([]byte{EncoderData, EncoderDataReportAuto, e})
+}
+
 // AnalogWrite writes value to pin.
 func (b *Client) AnalogWrite(pin int, value int) error {
 	b.pins[pin].Value = value
 	return b.write([]byte{AnalogMessage | byte(pin), byte(value & 0x7F), byte((value >> 7) & 0x7F)})
 }
 
+// ExtendedAnalogWrite writes value to pin with the ExtendedAnalog sysex,
+// which, unlike AnalogWrite, addresses pins above 15 and values wider than
+// 14 bits.
+func (b *Client) ExtendedAnalogWrite(pin int, value int) error {
+	b.pins[pin].Value = value
+	data := []byte{ExtendedAnalog, byte(pin), byte(value & 0x7F), byte((value >> 7) & 0x7F)}
+	for value >>= 14; value > 0; value >>= 7 {
+		data = append(data, byte(value&0x7F))
+	}
+	return b.WriteSysex(data)
+}
+
 // FirmwareQuery sends the FirmwareQuery sysex code.
 func (b *Client) FirmwareQuery() error {
 	return b.WriteSysex([]byte{FirmwareQuery})
@@ -324,6 +515,13 @@ func (b *Client) I2cRead(address int, numBytes int) error {
 		byte(numBytes) & 0x7F, (byte(numBytes) >> 7) & 0x7F})
 }
 
+// I2cReadRegister reads numBytes from register of address once.
+func (b *Client) I2cReadRegister(address int, register int, numBytes int) error {
+	return b.WriteSysex([]byte{I2CRequest, byte(address), (I2CModeRead << 3),
+		byte(register) & 0x7F, byte(register>>7) & 0x7F,
+		byte(numBytes) & 0x7F, byte(numBytes>>7) & 0x7F})
+}
+
 // I2cWrite writes data to address.
 func (b *Client) I2cWrite(address int, data []byte) error {
 	ret := []byte{I2CRequest, byte(address), (I2CModeWrite << 3)}
@@ -334,12 +532,40 @@ func (b *Client) I2cWrite(address int, data []byte) error {
 	return b.WriteSysex(ret)
 }
 
+// I2cTransfer writes data to address and then reads numBytes from it. When
+// data is a single register, it is sent with the read request and the
+// restart bit, so that StandardFirmata reads it after a repeated start
+// instead of a stop. Longer data is written in a request of its own, with a
+// stop before the read. Both requests are sent in one write, so that no
+// other request comes between them.
+func (b *Client) I2cTransfer(address int, data []byte, numBytes int) error {
+	if len(data) == 1 {
+		return b.WriteSysex([]byte{I2CRequest, byte(address), (I2CModeRead << 3) | I2CRestartTransmission,
+			data[0] & 0x7F, (data[0] >> 7) & 0x7F,
+			byte(numBytes) & 0x7F, byte(numBytes>>7) & 0x7F})
+	}
+	ret := []byte{StartSysex, I2CRequest, byte(address), (I2CModeWrite << 3)}
+	for _, val := range data {
+		ret = append(ret, byte(val&0x7F))
+		ret = append(ret, byte((val>>7)&0x7F))
+	}
+	ret = append(ret, EndSysex, StartSysex, I2CRequest, byte(address), (I2CModeRead << 3),
+		byte(numBytes)&0x7F, byte(numBytes>>7)&0x7F, EndSysex)
+	return b.write(ret)
+}
+
 // I2cConfig configures the delay in which a register can be read from after it
 // has been written to.
 func (b *Client) I2cConfig(delay int) error {
 	return b.WriteSysex([]byte{I2CConfig, byte(delay & 0xFF), byte((delay >> 8) & 0xFF)})
 }
 
+// SetSamplingInterval sets the interval in milliseconds at which the board
+// reports analog and continuous I2C reads.
+func (b *Client) SetSamplingInterval(interval int) error {
+	return b.WriteSysex([]byte{SamplingInterval, byte(interval & 0x7F), byte((interval >> 7) & 0x7F)})
+}
+
 func (b *Client) togglePinReporting(pin int, state int, mode byte) (err error) {
 	if state != 0 {
 		state = 1
@@ -356,8 +582,22 @@ func (b *Client) WriteSysex(data []byte) (err error) {
 	return b.write(append([]byte{StartSysex}, append(data, EndSysex)...))
 }
 
+// write sends a whole message, so that messages from drivers running
+// concurrently, such as two I2C devices, are never interleaved.
 func (b *Client) write(data []byte) (err error) {
-	_, err = b.connection.Write(data[:])
+	b.writeMutex.Lock()
+	defer b.writeMutex.Unlock()
+
+	for len(data) > 0 {
+		n, err := b.connection.Write(data)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return io.ErrShortWrite
+		}
+		data = data[n:]
+	}
 	return
 }
 
@@ -394,8 +634,10 @@ func (b *Client) process() (err error) {
 
 		if len(b.analogPins) > pin {
 			if len(b.pins) > b.analogPins[pin] {
+				b.pinsMutex.Lock()
 				b.pins[b.analogPins[pin]].Value = int(value)
-				b.Publish(b.Event(fmt.Sprintf("AnalogRead%v", pin)), b.pins[b.analogPins[pin]].Value)
+				b.pinsMutex.Unlock()
+				b.Publish(b.Event(fmt.Sprintf("AnalogRead%v", pin)), int(value))
 			}
 		}
 	case DigitalMessageRangeStart <= messageType &&
@@ -411,12 +653,16 @@ func (b *Client) process() (err error) {
 		for i := 0; i < 8; i++ {
 			pinNumber := int((8*byte(port) + byte(i)))
 			if len(b.pins) > pinNumber {
-				if b.pins[pinNumber].Mode == Input {
-					b.pins[pinNumber].Value = int((portValue >> (byte(i) & 0x07)) & 0x01)
-					b.Publish(b.Event(fmt.Sprintf("DigitalRead%v", pinNumber)), b.pins[pinNumber].Value)
+				if mode := b.pins[pinNumber].Mode; mode == Input || mode == Pullup {
+					value := int((portValue >> (byte(i) & 0x07)) & 0x01)
+					b.pinsMutex.Lock()
+					b.pins[pinNumber].Value = value
+					b.pinsMutex.Unlock()
+					b.Publish(b.Event(fmt.Sprintf("DigitalRead%v", pinNumber)), value)
 				}
 			}
 		}
+		b.Publish(b.Event(fmt.Sprintf("DigitalPortRead%v", port)), portValue)
 	case StartSysex == messageType:
 		buf, err := b.read(2)
 		if err != nil {
@@ -438,28 +684,27 @@ func (b *Client) process() (err error) {
 		switch command {
 		case CapabilityResponse:
 			b.pins = []Pin{}
-			supportedModes := 0
+			modes := []int{}
+			resolutions := map[int]int{}
 			n := 0
 
+			// each pin is a list of mode and resolution pairs, ended by 127
 			for _, val := range currentBuffer[2 : len(currentBuffer)-1] {
 				if val == 127 {
-					modes := []int{}
-					for _, mode := range []int{Input, Output, Analog, Pwm, Servo} {
-						if (supportedModes & (1 << byte(mode))) != 0 {
-							modes = append(modes, mode)
-						}
-					}
-
-					b.pins = append(b.pins, Pin{SupportedModes: modes, Mode: Output})
+					b.pins = append(b.pins, Pin{SupportedModes: modes, Mode: Output, Resolutions: resolutions})
 					b.AddEvent(fmt.Sprintf("DigitalRead%v", len(b.pins)-1))
 					b.AddEvent(fmt.Sprintf("PinState%v", len(b.pins)-1))
-					supportedModes = 0
+					b.AddEvent(fmt.Sprintf("DigitalPortRead%v", (len(b.pins)-1)/8))
+					modes = []int{}
+					resolutions = map[int]int{}
 					n = 0
 					continue
 				}
 
 				if n == 0 {
-					supportedModes = supportedModes | (1 << val)
+					modes = append(modes, int(val))
+				} else {
+					resolutions[modes[len(modes)-1]] = int(val)
 				}
 				n ^= 1
 			}
@@ -481,14 +726,13 @@ func (b *Client) process() (err error) {
 		case PinStateResponse:
 			pin := currentBuffer[2]
 			b.pins[pin].Mode = int(currentBuffer[3])
-			b.pins[pin].State = int(currentBuffer[4])
 
-			if len(currentBuffer) > 6 {
-				b.pins[pin].State = int(uint(b.pins[pin].State) | uint(currentBuffer[5])<<7)
-			}
-			if len(currentBuffer) > 7 {
-				b.pins[pin].State = int(uint(b.pins[pin].State) | uint(currentBuffer[6])<<14)
+			// the state is sent 7 bits per byte, least significant first
+			state := uint(0)
+			for i, val := range currentBuffer[4 : len(currentBuffer)-1] {
+				state |= uint(val) << (7 * uint(i))
 			}
+			b.pins[pin].State = int(state)
 
 			b.Publish(b.Event(fmt.Sprintf("PinState%v", pin)), b.pins[pin])
 		case I2CReply:
@@ -510,17 +754,38 @@ func (b *Client) process() (err error) {
 			}
 			b.Publish(b.Event("I2cReply"), reply)
 		case FirmwareQuery:
+			// the name is sent as two 7 bit bytes per character
 			name := []byte{}
-			for _, val := range currentBuffer[4:(len(currentBuffer) - 1)] {
-				if val != 0 {
-					name = append(name, val)
-				}
+			body := currentBuffer[4:(len(currentBuffer) - 1)]
+			for i := 0; i+1 < len(body); i += 2 {
+				name = append(name, body[i]|body[i+1]<<7)
 			}
 			b.FirmwareName = string(name[:])
 			b.Publish(b.Event("FirmwareQuery"), b.FirmwareName)
+			b.Publish(b.Event("Firmware"), Firmware{
+				Name:  b.FirmwareName,
+				Major: int(currentBuffer[2]),
+				Minor: int(currentBuffer[3]),
+			})
 		case StringData:
 			str := currentBuffer[2:]
 			b.Publish(b.Event("StringData"), string(str[:len(str)-1]))
+		case StepperData:
+			b.Publish(b.Event("StepperDone"), int(currentBuffer[2]))
+		case EncoderData:
+			// 5 bytes per encoder: the direction bit and encoder number, then
+			// the absolute position 7 bits per byte, least significant first
+			body := currentBuffer[2 : len(currentBuffer)-1]
+			for i := 0; i+5 <= len(body); i += 5 {
+				position := int(body[i+1]) | int(body[i+2])<<7 | int(body[i+3])<<14 | int(body[i+4])<<21
+				if body[i]&0x40 != 0 {
+					position = -position
+				}
+				b.Publish(b.Event("EncoderPosition"), EncoderPosition{
+					Encoder:  int(body[i] & 0x3F),
+					Position: position,
+				})
+			}
 		default:
 			data := make([]byte, len(currentBuffer))
 			copy(data, currentBuffer)
@@ -2,6 +2,8 @@ package client
 
 import (
 	"bytes"
+	"io"
+	"runtime"
 	"sync"
 	"testing"
 	"time"
@@ -102,6 +104,21 @@ func TestPins(t *testing.T) {
 	gobottest.Assert(t, len(b.analogPins), 6)
 }
 
+func TestProcessCapabilities(t *testing.T) {
+	b := initTestFirmata()
+	gobottest.Assert(t, b.Pins()[3].SupportedModes, []int{Input, Output, Pwm, Servo})
+	gobottest.Assert(t, b.Pins()[18].SupportedModes, []int{Input, Output, Analog, 0x06})
+	gobottest.Assert(t, b.Pins()[3].Resolutions, map[int]int{Input: 1, Output: 1, Pwm: 8, Servo: 14})
+	gobottest.Assert(t, b.Pins()[18].Resolutions, map[int]int{Input: 1, Output: 1, Analog: 10, 0x06: 1})
+
+	// modes beyond servo, such as tone, are kept
+	SetTestReadData([]byte{240, 108, 0, 1, 1, 1, Tone, 14, 127, 127, 247})
+	b.process()
+	gobottest.Assert(t, len(b.Pins()), 2)
+	gobottest.Assert(t, b.Pins()[0].SupportedModes, []int{Input, Output, Tone})
+	gobottest.Assert(t, b.Pins()[1].SupportedModes, []int{})
+}
+
 func TestReportVersion(t *testing.T) {
 	b := initTestFirmata()
 	b.setConnected(true)
@@ -225,12 +242,57 @@ func TestProcessDigitalRead4(t *testing.T) {
 	}
 }
 
+func TestProcessDigitalReadPullup(t *testing.T) {
+	b := initTestFirmata()
+	b.setConnected(true)
+	b.pins[3].Mode = Pullup
+	SetTestReadData([]byte{0x90, 0x08, 0x00})
+
+	b.process()
+	gobottest.Assert(t, b.pins[3].Value, 1)
+}
+
+func TestProcessDigitalPortRead1(t *testing.T) {
+	sem := make(chan bool)
+	b := initTestFirmata()
+	b.setConnected(true)
+	for i := 8; i < 16; i++ {
+		b.pins[i].Mode = Input
+	}
+	SetTestReadData([]byte{0x91, 0x05, 0x01})
+
+	b.Once(b.Event("DigitalPortRead1"), func(data interface{}) {
+		gobottest.Assert(t, data, byte(0x85))
+		sem <- true
+	})
+
+	b.process()
+
+	select {
+	case <-sem:
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("DigitalPortRead1 was not published")
+	}
+	gobottest.Assert(t, b.pins[8].Value, 1)
+	gobottest.Assert(t, b.pins[9].Value, 0)
+	gobottest.Assert(t, b.pins[10].Value, 1)
+}
+
 func TestDigitalWrite(t *testing.T) {
 	b := initTestFirmata()
 	b.setConnected(true)
 	gobottest.Assert(t, b.DigitalWrite(13, 0), nil)
 }
 
+func TestDigitalWritePins(t *testing.T) {
+	b := initTestFirmata()
+	b.setConnected(true)
+	testWriteData.Reset()
+	gobottest.Assert(t, b.DigitalWritePins(map[int]int{9: 1, 2: 1, 3: 1, 10: 1}), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0x90, 0x0C, 0x00, 0x91, 0x06, 0x00})
+	gobottest.Assert(t, b.Pins()[3].Value, 1)
+}
+
 func TestSetPinMode(t *testing.T) {
 	b := initTestFirmata()
 	b.setConnected(true)
@@ -243,6 +305,20 @@ func TestAnalogWrite(t *testing.T) {
 	gobottest.Assert(t, b.AnalogWrite(0, 128), nil)
 }
 
+func TestExtendedAnalogWrite(t *testing.T) {
+	b := initTestFirmata()
+	b.setConnected(true)
+	testWriteData.Reset()
+	gobottest.Assert(t, b.ExtendedAnalogWrite(3, 4095), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x6F, 3, 0x7F, 0x1F, 0xF7})
+	gobottest.Assert(t, b.Pins()[3].Value, 4095)
+
+	// values wider than 14 bits take more bytes
+	testWriteData.Reset()
+	gobottest.Assert(t, b.ExtendedAnalogWrite(3, 0x10000), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x6F, 3, 0x00, 0x00, 0x04, 0xF7})
+}
+
 func TestReportAnalog(t *testing.T) {
 	b := initTestFirmata()
 	b.setConnected(true)
@@ -257,7 +333,13 @@ func TestProcessPinState13(t *testing.T) {
 	SetTestReadData([]byte{240, 110, 13, 1, 1, 247})
 
 	b.Once(b.Event("PinState13"), func(data interface{}) {
-		gobottest.Assert(t, data, Pin{[]int{0, 1, 4}, 1, 0, 1, 127})
+		gobottest.Assert(t, data, Pin{
+			SupportedModes: []int{0, 1, 4},
+			Mode:           1,
+			State:          1,
+			AnalogChannel:  127,
+			Resolutions:    map[int]int{0: 1, 1: 1, 4: 14},
+		})
 		sem <- true
 	})
 
@@ -270,12 +352,122 @@ func TestProcessPinState13(t *testing.T) {
 	}
 }
 
+func TestProcessPinStatePwm(t *testing.T) {
+	sem := make(chan bool)
+	b := initTestFirmata()
+	b.setConnected(true)
+	// the state is 0x01 | 0x02<<7 | 0x03<<14 | 0x04<<21, sent in four bytes
+	SetTestReadData([]byte{240, 110, 3, 3, 1, 2, 3, 4, 247})
+
+	b.Once(b.Event("PinState3"), func(data interface{}) {
+		gobottest.Assert(t, data.(Pin).Mode, Pwm)
+		gobottest.Assert(t, data.(Pin).State, 8438017)
+		sem <- true
+	})
+
+	b.process()
+
+	select {
+	case <-sem:
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("PinState3 was not published")
+	}
+}
+
 func TestI2cConfig(t *testing.T) {
 	b := initTestFirmata()
 	b.setConnected(true)
 	gobottest.Assert(t, b.I2cConfig(100), nil)
 }
 
+func TestSetSamplingInterval(t *testing.T) {
+	b := initTestFirmata()
+	b.setConnected(true)
+	testWriteData.Reset()
+	gobottest.Assert(t, b.SetSamplingInterval(1000), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x7A, 0x68, 0x07, 0xF7})
+}
+
+func TestTone(t *testing.T) {
+	b := initTestFirmata()
+	b.setConnected(true)
+	testWriteData.Reset()
+	gobottest.Assert(t, b.Tone(8, 440, 1000), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x5F, 0x00, 8, 0x38, 0x03, 0x68, 0x07, 0xF7})
+
+	testWriteData.Reset()
+	gobottest.Assert(t, b.NoTone(8), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x5F, 0x01, 8, 0xF7})
+}
+
+func TestStepper(t *testing.T) {
+	b := initTestFirmata()
+	b.setConnected(true)
+	testWriteData.Reset()
+	gobottest.Assert(t, b.StepperConfig(1, StepperDriver, 200, 2, 3), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x72, 0x00, 1, 0x01, 0x48, 0x01, 2, 3, 0xF7})
+
+	testWriteData.Reset()
+	gobottest.Assert(t, b.StepperStep(1, 1, 20000, 1000), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x72, 0x01, 1, 1, 0x20, 0x1C, 0x01, 0x68, 0x07, 0xF7})
+}
+
+func TestProcessStepperDone(t *testing.T) {
+	sem := make(chan bool)
+	b := initTestFirmata()
+	SetTestReadData([]byte{240, 0x72, 3, 247})
+
+	b.Once(b.Event("StepperDone"), func(data interface{}) {
+		gobottest.Assert(t, data, 3)
+		sem <- true
+	})
+
+	b.process()
+
+	select {
+	case <-sem:
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("StepperDone was not published")
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	b := initTestFirmata()
+	b.setConnected(true)
+	testWriteData.Reset()
+	gobottest.Assert(t, b.EncoderAttach(1, 2, 3), nil)
+	gobottest.Assert(t, b.EncoderReportAuto(true), nil)
+	gobottest.Assert(t, b.EncoderReset(1), nil)
+	gobottest.Assert(t, b.EncoderDetach(1), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{
+		0xF0, 0x61, 0x00, 1, 2, 3, 0xF7,
+		0xF0, 0x61, 0x04, 1, 0xF7,
+		0xF0, 0x61, 0x03, 1, 0xF7,
+		0xF0, 0x61, 0x05, 1, 0xF7,
+	})
+}
+
+func TestProcessEncoderPositions(t *testing.T) {
+	b := initTestFirmata()
+	positions := make(chan EncoderPosition, 2)
+	b.On(b.Event("EncoderPosition"), func(data interface{}) {
+		positions <- data.(EncoderPosition)
+	})
+
+	// encoder 0 at 300, encoder 1 at -2097152
+	SetTestReadData([]byte{240, 0x61, 0x00, 0x2C, 0x02, 0x00, 0x00, 0x41, 0x00, 0x00, 0x00, 0x01, 247})
+	b.process()
+
+	for _, expected := range []EncoderPosition{{0, 300}, {1, -2097152}} {
+		select {
+		case position := <-positions:
+			gobottest.Assert(t, position, expected)
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("EncoderPosition was not published")
+		}
+	}
+}
+
 func TestI2cWrite(t *testing.T) {
 	b := initTestFirmata()
 	b.setConnected(true)
@@ -288,6 +480,74 @@ func TestI2cRead(t *testing.T) {
 	gobottest.Assert(t, b.I2cRead(0x00, 10), nil)
 }
 
+func TestI2cReadRegister(t *testing.T) {
+	b := initTestFirmata()
+	b.setConnected(true)
+	testWriteData.Reset()
+	gobottest.Assert(t, b.I2cReadRegister(0x68, 0x3B, 14), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x76, 0x68, 0x08, 0x3B, 0x00, 0x0E, 0x00, 0xF7})
+}
+
+func TestI2cTransfer(t *testing.T) {
+	b := initTestFirmata()
+	b.setConnected(true)
+	testWriteData.Reset()
+	gobottest.Assert(t, b.I2cTransfer(0x68, []byte{0x3B, 0x80}, 14), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{
+		0xF0, 0x76, 0x68, 0x00, 0x3B, 0x00, 0x00, 0x01, 0xF7,
+		0xF0, 0x76, 0x68, 0x08, 0x0E, 0x00, 0xF7,
+	})
+
+	testWriteData.Reset()
+	gobottest.Assert(t, b.I2cTransfer(0x68, []byte{0x3B}, 14), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x76, 0x68, 0x48, 0x3B, 0x00, 0x0E, 0x00, 0xF7})
+}
+
+// chunkedWriter accepts at most two bytes per Write, like a busy serial port
+type chunkedWriter struct {
+	readWriteCloser
+	mtx  sync.Mutex
+	data []byte
+}
+
+func (c *chunkedWriter) Write(p []byte) (int, error) {
+	if len(p) > 2 {
+		p = p[:2]
+	}
+	c.mtx.Lock()
+	c.data = append(c.data, p...)
+	c.mtx.Unlock()
+	time.Sleep(time.Microsecond)
+	return len(p), nil
+}
+
+func TestConcurrentI2cWrites(t *testing.T) {
+	b := initTestFirmata()
+	b.setConnected(true)
+	w := &chunkedWriter{}
+	b.connection = w
+
+	var wg sync.WaitGroup
+	for _, address := range []int{0x10, 0x20} {
+		wg.Add(1)
+		go func(address int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				b.I2cWrite(address, []byte{0x01, 0x02, 0x03})
+			}
+		}(address)
+	}
+	wg.Wait()
+
+	gobottest.Assert(t, len(w.data), 20*11)
+	for i := 0; i < len(w.data); i += 11 {
+		message := w.data[i : i+11]
+		gobottest.Assert(t, message[0], StartSysex)
+		gobottest.Assert(t, message[2] == 0x10 || message[2] == 0x20, true)
+		gobottest.Assert(t, message[10], EndSysex)
+	}
+}
+
 func TestWriteSysex(t *testing.T) {
 	b := initTestFirmata()
 	b.setConnected(true)
@@ -330,13 +590,19 @@ func TestProcessFirmwareQuery(t *testing.T) {
 		gobottest.Assert(t, data, "StandardFirmata.ino")
 		sem <- true
 	})
+	b.Once(b.Event("Firmware"), func(data interface{}) {
+		gobottest.Assert(t, data, Firmware{Name: "StandardFirmata.ino", Major: 2, Minor: 3})
+		sem <- true
+	})
 
 	b.process()
 
-	select {
-	case <-sem:
-	case <-time.After(100 * time.Millisecond):
-		t.Errorf("FirmwareQuery was not published")
+	for i := 0; i < 2; i++ {
+		select {
+		case <-sem:
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("FirmwareQuery was not published")
+		}
 	}
 }
 
@@ -408,6 +674,53 @@ func TestConnect(t *testing.T) {
 	gobottest.Assert(t, b.Disconnect(), nil)
 }
 
+func TestConnectResetOnConnect(t *testing.T) {
+	gobottest.Assert(t, New().ResetOnConnect, true)
+
+	for _, reset := range []bool{true, false} {
+		b := New()
+		b.ConnectTimeout = 10 * time.Millisecond
+		b.ResetOnConnect = reset
+
+		writeDataMutex.Lock()
+		testWriteData.Reset()
+		writeDataMutex.Unlock()
+		gobottest.Refute(t, b.Connect(readWriteCloser{}), nil)
+
+		writeDataMutex.Lock()
+		written := testWriteData.Bytes()
+		if reset {
+			gobottest.Assert(t, written[:2], []byte{SystemReset, ProtocolVersion})
+		} else {
+			gobottest.Assert(t, written[:1], []byte{ProtocolVersion})
+		}
+		writeDataMutex.Unlock()
+	}
+}
+
+// silentReadWriteCloser blocks reading until it is closed, like a serial
+// port to a board which does not run Firmata
+type silentReadWriteCloser struct {
+	*io.PipeReader
+}
+
+func (silentReadWriteCloser) Write(p []byte) (int, error) { return len(p), nil }
+
+func TestConnectTimeoutsDoNotLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	// the handshake handlers are removed on each timeout, only the goroutine
+	// of the Eventer of each Client is left
+	for i := 0; i < 20; i++ {
+		b := New()
+		b.ConnectTimeout = time.Millisecond
+		r, _ := io.Pipe()
+		gobottest.Refute(t, b.Connect(silentReadWriteCloser{r}), nil)
+		r.Close()
+	}
+	gobottest.AssertMaxGoroutines(t, before+20)
+}
+
 func TestServoConfig(t *testing.T) {
 	b := New()
 	b.connection = readWriteCloser{}
@@ -0,0 +1,150 @@
+package firmata
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gobot.io/x/gobot"
+)
+
+// DHT sensor types
+const (
+	DHT11 = 11
+	DHT22 = 22
+)
+
+// DHTData is the sysex command used to read a DHT sensor. StandardFirmata
+// cannot meet the timing of the DHT protocol, the board must run firmware
+// which answers DHT_DATA, pin and sensor type with DHT_DATA, pin and the 5
+// bytes read from the sensor, each sent as two 7 bit bytes.
+const DHTData = 0x74
+
+// ErrDHTChecksum is the error returned when the checksum sent by a DHT
+// sensor does not match its data
+var ErrDHTChecksum = errors.New("DHT checksum mismatch")
+
+var _ gobot.Driver = (*DHTDriver)(nil)
+
+// DHTDriver represents a DHT11 or DHT22 temperature and humidity sensor
+// connected to a board running firmware with DHT support
+type DHTDriver struct {
+	name       string
+	pin        string
+	sensorType int
+	connection FirmataAdaptor
+
+	// Timeout is how long Read waits for the board to answer, one second by
+	// default
+	Timeout time.Duration
+	gobot.Eventer
+}
+
+// NewDHTDriver returns a new DHTDriver given a FirmataAdaptor, the pin the
+// sensor is connected to and the sensor type, DHT11 or DHT22.
+func NewDHTDriver(a FirmataAdaptor, pin string, sensorType int) *DHTDriver {
+	d := &DHTDriver{
+		name:       gobot.DefaultName("DHT"),
+		pin:        pin,
+		sensorType: sensorType,
+		connection: a,
+		Timeout:    time.Second,
+		Eventer:    gobot.NewEventer(),
+	}
+
+	d.AddEvent("temperature")
+	d.AddEvent("humidity")
+	return d
+}
+
+// Start implements the Driver interface
+func (d *DHTDriver) Start() (err error) {
+	if d.sensorType != DHT11 && d.sensorType != DHT22 {
+		return fmt.Errorf("Invalid DHT sensor type %d", d.sensorType)
+	}
+	return
+}
+
+// Halt implements the Driver interface
+func (d *DHTDriver) Halt() (err error) { return }
+
+// Name returns the DHTDriver name
+func (d *DHTDriver) Name() string { return d.name }
+
+// SetName sets the DHTDriver name
+func (d *DHTDriver) SetName(n string) { d.name = n }
+
+// Pin returns the DHTDriver pin
+func (d *DHTDriver) Pin() string { return d.pin }
+
+// Connection returns the DHTDriver Connection
+func (d *DHTDriver) Connection() gobot.Connection { return d.connection }
+
+// Read reads the temperature, in degrees Celsius, and the relative humidity,
+// in percent, from the sensor, and publishes them as "temperature" and
+// "humidity" events. ErrDHTChecksum is returned when the data is corrupted.
+// The sensors must not be read more than once per second, or every two
+// seconds for the DHT22.
+func (d *DHTDriver) Read() (temperature, humidity float64, err error) {
+	pin, err := strconv.Atoi(d.pin)
+	if err != nil {
+		return
+	}
+
+	events := d.connection.Subscribe()
+	defer d.connection.Unsubscribe(events)
+
+	if err = d.connection.WriteSysex([]byte{DHTData, byte(pin), byte(d.sensorType)}); err != nil {
+		return
+	}
+
+	timeout := time.After(d.Timeout)
+	for {
+		select {
+		case evt := <-events:
+			data, ok := evt.Data.([]byte)
+			if evt.Name != "SysexResponse" || !ok || len(data) < 3 || data[1] != DHTData || int(data[2]) != pin {
+				continue
+			}
+			if temperature, humidity, err = d.parse(data); err != nil {
+				return
+			}
+			d.Publish("temperature", temperature)
+			d.Publish("humidity", humidity)
+			return
+		case <-timeout:
+			return 0, 0, ErrReadTimeout
+		}
+	}
+}
+
+// parse decodes the sysex response holding the 5 bytes read from the sensor
+func (d *DHTDriver) parse(data []byte) (temperature, humidity float64, err error) {
+	if len(data) != 14 {
+		return 0, 0, fmt.Errorf("Invalid DHT response length %d", len(data))
+	}
+	b := make([]byte, 5)
+	for i := range b {
+		b[i] = data[3+2*i] | data[4+2*i]<<7
+	}
+	if b[0]+b[1]+b[2]+b[3] != b[4] {
+		return 0, 0, ErrDHTChecksum
+	}
+
+	if d.sensorType == DHT11 {
+		humidity = float64(b[0]) + float64(b[1])/10
+		temperature = float64(b[2]) + float64(b[3]&0x7F)/10
+		if b[3]&0x80 != 0 {
+			temperature = -temperature
+		}
+		return
+	}
+
+	humidity = float64(uint16(b[0])<<8|uint16(b[1])) / 10
+	temperature = float64(uint16(b[2]&0x7F)<<8|uint16(b[3])) / 10
+	if b[2]&0x80 != 0 {
+		temperature = -temperature
+	}
+	return
+}
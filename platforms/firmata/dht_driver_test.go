@@ -0,0 +1,118 @@
+package firmata
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gobot.io/x/gobot"
+	"gobot.io/x/gobot/gobottest"
+)
+
+var _ gobot.Driver = (*DHTDriver)(nil)
+
+// dhtTestAdaptor answers every sysex with the response
+type dhtTestAdaptor struct {
+	response []byte
+	writes   [][]byte
+	gobot.Eventer
+}
+
+func (a *dhtTestAdaptor) Connect() (err error)  { return }
+func (a *dhtTestAdaptor) Finalize() (err error) { return }
+func (a *dhtTestAdaptor) Name() string          { return "" }
+func (a *dhtTestAdaptor) SetName(n string)      {}
+func (a *dhtTestAdaptor) WriteSysex(data []byte) error {
+	a.writes = append(a.writes, data)
+	if a.response != nil {
+		go a.Publish("SysexResponse", a.response)
+	}
+	return nil
+}
+
+// dhtResponse returns the sysex response of the board for the sensor bytes
+func dhtResponse(pin byte, b ...byte) []byte {
+	data := []byte{0xF0, DHTData, pin}
+	for _, val := range b {
+		data = append(data, val&0x7F, val>>7)
+	}
+	return append(data, 0xF7)
+}
+
+func initTestDHTDriver(sensorType int, response []byte) (*DHTDriver, *dhtTestAdaptor) {
+	a := &dhtTestAdaptor{response: response, Eventer: gobot.NewEventer()}
+	d := NewDHTDriver(a, "7", sensorType)
+	d.Timeout = 10 * time.Millisecond
+	return d, a
+}
+
+func TestDHTDriver(t *testing.T) {
+	d, _ := initTestDHTDriver(DHT22, nil)
+	gobottest.Assert(t, strings.HasPrefix(d.Name(), "DHT"), true)
+	d.SetName("mydht")
+	gobottest.Assert(t, d.Name(), "mydht")
+	gobottest.Assert(t, d.Pin(), "7")
+	gobottest.Refute(t, d.Connection(), nil)
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, d.Halt(), nil)
+
+	d, _ = initTestDHTDriver(12, nil)
+	gobottest.Assert(t, d.Start(), errors.New("Invalid DHT sensor type 12"))
+}
+
+func TestDHTDriverReadDHT22(t *testing.T) {
+	d, a := initTestDHTDriver(DHT22, dhtResponse(7, 0x02, 0x8C, 0x80, 0x65, 0x73))
+	events := make(chan float64, 2)
+	d.On("temperature", func(data interface{}) { events <- data.(float64) })
+	d.On("humidity", func(data interface{}) { events <- data.(float64) })
+
+	temperature, humidity, err := d.Read()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temperature, -10.1)
+	gobottest.Assert(t, humidity, 65.2)
+	gobottest.Assert(t, a.writes, [][]byte{{DHTData, 7, DHT22}})
+
+	published := []float64{}
+	for i := 0; i < 2; i++ {
+		select {
+		case val := <-events:
+			published = append(published, val)
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("DHT reading was not published")
+		}
+	}
+	gobottest.Assert(t, len(published), 2)
+}
+
+func TestDHTDriverReadDHT11(t *testing.T) {
+	d, _ := initTestDHTDriver(DHT11, dhtResponse(7, 40, 0, 23, 5, 68))
+	temperature, humidity, err := d.Read()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temperature, 23.5)
+	gobottest.Assert(t, humidity, 40.0)
+}
+
+func TestDHTDriverReadChecksum(t *testing.T) {
+	d, _ := initTestDHTDriver(DHT11, dhtResponse(7, 40, 0, 23, 5, 69))
+	_, _, err := d.Read()
+	gobottest.Assert(t, err, ErrDHTChecksum)
+}
+
+func TestDHTDriverReadTimeout(t *testing.T) {
+	// responses for other pins are ignored
+	d, _ := initTestDHTDriver(DHT11, dhtResponse(8, 40, 0, 23, 5, 68))
+	_, _, err := d.Read()
+	gobottest.Assert(t, err, ErrReadTimeout)
+
+	d, _ = initTestDHTDriver(DHT11, nil)
+	_, _, err = d.Read()
+	gobottest.Assert(t, err, ErrReadTimeout)
+}
+
+func TestDHTDriverReadBadPin(t *testing.T) {
+	d, _ := initTestDHTDriver(DHT11, nil)
+	d.pin = "a"
+	_, _, err := d.Read()
+	gobottest.Refute(t, err, nil)
+}
@@ -1,9 +1,12 @@
 package firmata
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	serial "go.bug.st/serial.v1"
@@ -12,19 +15,43 @@ import (
 	"gobot.io/x/gobot/platforms/firmata/client"
 )
 
+// ErrReadTimeout is the error returned when the board does not reply to a
+// read within the ReadTimeout of the Adaptor
+var ErrReadTimeout = errors.New("Timed out waiting for the board to reply")
+
+// ErrToneUnsupported is the error returned by Tone when the capabilities
+// reported by the board show its firmware has no tone feature
+var ErrToneUnsupported = errors.New("Firmware does not support tone")
+
 type firmataBoard interface {
 	Connect(io.ReadWriteCloser) error
 	Disconnect() error
 	Pins() []client.Pin
 	AnalogWrite(int, int) error
+	ExtendedAnalogWrite(int, int) error
 	SetPinMode(int, int) error
 	ReportAnalog(int, int) error
 	ReportDigital(int, int) error
+	FirmwareQuery() error
+	AnalogMappingQuery() error
+	PinStateQuery(int) error
+	SetSamplingInterval(int) error
 	DigitalWrite(int, int) error
+	DigitalWritePins(map[int]int) error
 	I2cRead(int, int) error
+	I2cReadRegister(int, int, int) error
 	I2cWrite(int, []byte) error
+	I2cTransfer(int, []byte, int) error
 	I2cConfig(int) error
 	ServoConfig(int, int, int) error
+	Tone(int, int, int) error
+	NoTone(int) error
+	StepperConfig(int, int, int, int, int) error
+	StepperStep(int, int, int, int) error
+	EncoderAttach(int, int, int) error
+	EncoderReset(int) error
+	EncoderDetach(int) error
+	EncoderReportAuto(bool) error
 	WriteSysex(data []byte) error
 	gobot.Eventer
 }
@@ -42,46 +69,107 @@ type FirmataAdaptor interface {
 type Adaptor struct {
 	name       string
 	port       string
+	baud       int
+	mode       *serial.Mode
 	Board      firmataBoard
 	conn       io.ReadWriteCloser
 	PortOpener func(port string) (io.ReadWriteCloser, error)
+
+	// ReadTimeout is how long reads wait for the board to reply, 10ms by
+	// default. Raise it for slow boards or noisy links.
+	ReadTimeout time.Duration
+
+	// ResetOnConnect makes Connect send SYSTEM_RESET to the board before the
+	// handshake, clearing the pin reporting and servos left by a previous
+	// session. It is enabled by default, disable it to attach to a board
+	// without disturbing the program driving it.
+	ResetOnConnect bool
+
+	// I2cReadTimeout is how long I2C reads wait for the reply of the device,
+	// one second by default. An I2C read is a full round trip over the
+	// serial link and the I2C bus, which often takes longer than ReadTimeout.
+	I2cReadTimeout time.Duration
+
+	// DigitalWriteWindow enables write coalescing when non zero: DigitalWrite
+	// calls made within the window are sent together as one DIGITAL_MESSAGE
+	// per port once the window elapses, or when Flush is called.
+	DigitalWriteWindow time.Duration
+	queuedWrites       map[int]int
+	flushTimer         *time.Timer
+	mtx                sync.Mutex
+
+	analogMapping map[int]int
+	encoders      map[int]int
+	analogStreams map[int]chan struct{}
+	servoConfigs  map[int][2]int
+
+	portOpened        bool
+	subscribed        bool
+	reconnecting      bool
+	finalized         bool
+	reconnectInterval time.Duration
 	gobot.Eventer
 }
 
 // NewAdaptor returns a new Firmata Adaptor which optionally accepts:
 //
-//	string: port the Adaptor uses to connect to a serial port with a baude rate of 57600
+//	string: port the Adaptor uses to connect to a serial port, or to a board
+//	  running Firmata over TCP when prefixed with "tcp://", as in "tcp://host:port"
+//	int: baud rate of the serial port, defaults to 57600
+//	*serial.Mode: data bits, parity and stop bits of the serial port, and its baud
+//	  rate when set, for USB-serial chips which need other than the defaults
 //	io.ReadWriteCloser: connection the Adaptor uses to communication with the hardware
+//	time.Duration: read timeout, how long reads wait for the board to reply
 //
 // If an io.ReadWriteCloser is not supplied, the Adaptor will open a connection
-// to a serial port with a baude rate of 57600. If an io.ReadWriteCloser
+// to the serial port with the baud rate. If an io.ReadWriteCloser
 // is supplied, then the Adaptor will use the provided io.ReadWriteCloser and use the
 // string port as a label to be displayed in the log and api.
 func NewAdaptor(args ...interface{}) *Adaptor {
 	f := &Adaptor{
-		name:  gobot.DefaultName("Firmata"),
-		port:  "",
-		conn:  nil,
-		Board: client.New(),
-		PortOpener: func(port string) (io.ReadWriteCloser, error) {
-			return serial.Open(port, &serial.Mode{BaudRate: 57600})
-		},
-		Eventer: gobot.NewEventer(),
+		name:           gobot.DefaultName("Firmata"),
+		port:           "",
+		baud:           57600,
+		conn:           nil,
+		Board:          client.New(),
+		ReadTimeout:    10 * time.Millisecond,
+		I2cReadTimeout: time.Second,
+		ResetOnConnect: true,
+		Eventer:        gobot.NewEventer(),
+
+		reconnectInterval: time.Second,
+	}
+	f.PortOpener = func(port string) (io.ReadWriteCloser, error) {
+		if strings.HasPrefix(port, tcpScheme) {
+			return connect(strings.TrimPrefix(port, tcpScheme))
+		}
+		return serial.Open(port, f.serialMode())
 	}
 
 	for _, arg := range args {
 		switch arg.(type) {
 		case string:
 			f.port = arg.(string)
+		case int:
+			f.baud = arg.(int)
+		case *serial.Mode:
+			mode := *arg.(*serial.Mode)
+			f.mode = &mode
+			if mode.BaudRate != 0 {
+				f.baud = mode.BaudRate
+			}
 		case io.ReadWriteCloser:
 			f.conn = arg.(io.ReadWriteCloser)
+		case time.Duration:
+			f.ReadTimeout = arg.(time.Duration)
 		}
 	}
 
 	return f
 }
 
-// Connect starts a connection to the board.
+// Connect starts a connection to the board. Boards connected over TCP are
+// reconnected when the connection drops.
 func (f *Adaptor) Connect() (err error) {
 	if f.conn == nil {
 		sp, e := f.PortOpener(f.Port())
@@ -89,28 +177,112 @@ func (f *Adaptor) Connect() (err error) {
 			return e
 		}
 		f.conn = sp
+		f.portOpened = true
+	}
+	if board, ok := f.Board.(*client.Client); ok {
+		board.ResetOnConnect = f.ResetOnConnect
 	}
 	if err = f.Board.Connect(f.conn); err != nil {
 		return err
 	}
 
+	f.mtx.Lock()
+	subscribed := f.subscribed
+	f.subscribed = true
+	f.mtx.Unlock()
+	if subscribed {
+		return
+	}
+
 	f.Board.On("SysexResponse", func(data interface{}) {
 		f.Publish("SysexResponse", data)
 	})
 
+	f.Board.On("StepperDone", func(data interface{}) {
+		f.Publish("stepper_done", data)
+	})
+
+	f.Board.On("EncoderPosition", func(data interface{}) {
+		if position, ok := data.(client.EncoderPosition); ok {
+			f.publishEncoderPosition(position)
+		}
+	})
+
+	if strings.HasPrefix(f.Port(), tcpScheme) {
+		f.Board.On("Error", func(data interface{}) {
+			if data == io.EOF || data == io.ErrUnexpectedEOF {
+				go f.keepConnected()
+			}
+		})
+	}
+
 	return
 }
 
-// Disconnect closes the io connection to the Board
+// Reconnect closes the connection to the board and connects again. Only
+// connections opened by the Adaptor can be reconnected.
+func (f *Adaptor) Reconnect() (err error) {
+	if !f.portOpened {
+		return errors.New("Only connections opened by the Adaptor can be reconnected")
+	}
+
+	f.Disconnect()
+	f.conn = nil
+	return f.Connect()
+}
+
+// keepConnected reconnects to the board until it succeeds or the Adaptor is
+// finalized.
+func (f *Adaptor) keepConnected() {
+	f.mtx.Lock()
+	if f.reconnecting || f.finalized {
+		f.mtx.Unlock()
+		return
+	}
+	f.reconnecting = true
+	f.mtx.Unlock()
+
+	defer func() {
+		f.mtx.Lock()
+		f.reconnecting = false
+		f.mtx.Unlock()
+	}()
+
+	for {
+		err := f.Reconnect()
+		if err == nil {
+			return
+		}
+		f.Publish("Error", err)
+
+		time.Sleep(f.reconnectInterval)
+		f.mtx.Lock()
+		finalized := f.finalized
+		f.mtx.Unlock()
+		if finalized {
+			return
+		}
+	}
+}
+
+// Disconnect closes the io connection to the Board. Digital writes still
+// queued in the write window are sent first.
 func (f *Adaptor) Disconnect() (err error) {
 	if f.Board != nil {
-		return f.Board.Disconnect()
+		flushErr := f.Flush()
+		if err = f.Board.Disconnect(); err != nil {
+			return
+		}
+		return flushErr
 	}
 	return nil
 }
 
 // Finalize terminates the firmata connection
 func (f *Adaptor) Finalize() (err error) {
+	f.mtx.Lock()
+	f.finalized = true
+	f.mtx.Unlock()
 	err = f.Disconnect()
 	return err
 }
@@ -118,31 +290,76 @@ func (f *Adaptor) Finalize() (err error) {
 // Port returns the Firmata Adaptors port
 func (f *Adaptor) Port() string { return f.port }
 
+// Baud returns the Firmata Adaptors serial baud rate
+func (f *Adaptor) Baud() int { return f.baud }
+
+// serialMode returns the mode the serial port is opened with: the one given
+// to NewAdaptor, if any, at the baud rate of the Adaptor.
+func (f *Adaptor) serialMode() *serial.Mode {
+	mode := &serial.Mode{}
+	if f.mode != nil {
+		*mode = *f.mode
+	}
+	mode.BaudRate = f.baud
+	return mode
+}
+
 // Name returns the Firmata Adaptors name
 func (f *Adaptor) Name() string { return f.name }
 
 // SetName sets the Firmata Adaptors name
 func (f *Adaptor) SetName(n string) { f.name = n }
 
-// ServoConfig sets the pulse width in microseconds for a pin attached to a servo
+// ServoConfig sets the pulse width in microseconds for a pin attached to a
+// servo, and attaches the servo. The range is kept, and sent again when
+// ServoWrite later switches the pin back to servo mode.
 func (f *Adaptor) ServoConfig(pin string, min, max int) error {
 	p, err := strconv.Atoi(pin)
 	if err != nil {
 		return err
 	}
+	if p < 0 || p >= len(f.Board.Pins()) {
+		return fmt.Errorf("pin %d does not exist", p)
+	}
+	if min < 0 || min > max {
+		return fmt.Errorf("Invalid servo pulse width range %d-%d", min, max)
+	}
+
+	if err = f.Board.ServoConfig(p, max, min); err != nil {
+		return err
+	}
 
-	return f.Board.ServoConfig(p, max, min)
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.servoConfigs == nil {
+		f.servoConfigs = make(map[int][2]int)
+	}
+	f.servoConfigs[p] = [2]int{min, max}
+	// the board sets the pin to servo mode when configuring it
+	f.Board.Pins()[p].Mode = client.Servo
+	return nil
 }
 
-// ServoWrite writes the 0-180 degree angle to the specified pin.
+// ServoWrite writes the 0-180 degree angle to the specified pin. Angles
+// above 180 are rejected, they would drive the servo past its range.
 func (f *Adaptor) ServoWrite(pin string, angle byte) (err error) {
 	p, err := strconv.Atoi(pin)
 	if err != nil {
 		return err
 	}
+	if angle > 180 {
+		return fmt.Errorf("Invalid servo angle %d, must be between 0 and 180", angle)
+	}
 
 	if f.Board.Pins()[p].Mode != client.Servo {
-		err = f.Board.SetPinMode(p, client.Servo)
+		f.mtx.Lock()
+		config, ok := f.servoConfigs[p]
+		f.mtx.Unlock()
+		if ok {
+			err = f.ServoConfig(pin, config[0], config[1])
+		} else {
+			err = f.setPinMode(p, ServoMode)
+		}
 		if err != nil {
 			return err
 		}
@@ -151,7 +368,7 @@ func (f *Adaptor) ServoWrite(pin string, angle byte) (err error) {
 	return
 }
 
-// PwmWrite writes the 0-254 value to the specified pin
+// PwmWrite writes the 0-255 level to the specified pin
 func (f *Adaptor) PwmWrite(pin string, level byte) (err error) {
 	p, err := strconv.Atoi(pin)
 	if err != nil {
@@ -159,7 +376,7 @@ func (f *Adaptor) PwmWrite(pin string, level byte) (err error) {
 	}
 
 	if f.Board.Pins()[p].Mode != client.Pwm {
-		err = f.Board.SetPinMode(p, client.Pwm)
+		err = f.setPinMode(p, PwmMode)
 		if err != nil {
 			return err
 		}
@@ -168,6 +385,221 @@ func (f *Adaptor) PwmWrite(pin string, level byte) (err error) {
 	return
 }
 
+// PwmWrite16 writes the level to the specified pin, at the PWM resolution
+// the board reported for the pin, such as 0-4095 for the 12 bit PWM of an
+// ESP32. Levels above 255, and pins above 15, are written with the extended
+// analog sysex of ConfigurableFirmata, others as PwmWrite does.
+func (f *Adaptor) PwmWrite16(pin string, level uint16) (err error) {
+	p, err := strconv.Atoi(pin)
+	if err != nil {
+		return err
+	}
+	if p < 0 || p >= len(f.Board.Pins()) {
+		return fmt.Errorf("pin %d does not exist", p)
+	}
+	if bits := f.Board.Pins()[p].Resolutions[client.Pwm]; bits > 0 && bits < 16 && int(level) >= 1<<uint(bits) {
+		return fmt.Errorf("Invalid PWM level %d, must be between 0 and %d", level, 1<<uint(bits)-1)
+	}
+
+	if f.Board.Pins()[p].Mode != client.Pwm {
+		if err = f.setPinMode(p, PwmMode); err != nil {
+			return err
+		}
+	}
+	if level > 255 || p > 15 {
+		return f.Board.ExtendedAnalogWrite(p, int(level))
+	}
+	return f.Board.AnalogWrite(p, int(level))
+}
+
+// Tone plays a square wave of frequency Hz on the pin, such as a beep on a
+// piezo buzzer, for durationMs milliseconds or until NoTone when durationMs
+// is 0. It requires firmware with the tone feature, such as
+// ConfigurableFirmata, ErrToneUnsupported is returned when the board
+// reported it has none.
+func (f *Adaptor) Tone(pin string, frequency int, durationMs int) error {
+	p, err := strconv.Atoi(pin)
+	if err != nil {
+		return err
+	}
+	if p < 0 || p >= len(f.Board.Pins()) {
+		return fmt.Errorf("pin %d does not exist", p)
+	}
+	if frequency < 1 || frequency > 0x3FFF {
+		return fmt.Errorf("Invalid tone frequency %dHz, must be between 1 and 16383Hz", frequency)
+	}
+	if durationMs < 0 || durationMs > 0x3FFF {
+		return fmt.Errorf("Invalid tone duration %dms, must be between 0 and 16383ms", durationMs)
+	}
+
+	if f.Board.Pins()[p].Mode != client.Tone {
+		if !f.supportsMode(ToneMode) {
+			return ErrToneUnsupported
+		}
+		if err = f.setPinMode(p, ToneMode); err != nil {
+			return err
+		}
+	}
+	return f.Board.Tone(p, frequency, durationMs)
+}
+
+// NoTone stops the tone played on the pin.
+func (f *Adaptor) NoTone(pin string) error {
+	p, err := strconv.Atoi(pin)
+	if err != nil {
+		return err
+	}
+	if p < 0 || p >= len(f.Board.Pins()) {
+		return fmt.Errorf("pin %d does not exist", p)
+	}
+	return f.Board.NoTone(p)
+}
+
+// StepperConfig configures the stepper deviceNum, from 0 to
+// client.MaxSteppers-1, wired to dirPin and stepPin through stepType, one of
+// client.StepperDriver or client.StepperTwoWire. It requires firmware with
+// the stepper feature, such as ConfigurableFirmata.
+func (f *Adaptor) StepperConfig(deviceNum, stepType int, stepsPerRev int, dirPin, stepPin string) error {
+	if deviceNum < 0 || deviceNum >= client.MaxSteppers {
+		return fmt.Errorf("Invalid stepper device %d, must be between 0 and %d", deviceNum, client.MaxSteppers-1)
+	}
+	if stepType != client.StepperDriver && stepType != client.StepperTwoWire {
+		return fmt.Errorf("Invalid stepper type %d", stepType)
+	}
+	if stepsPerRev < 1 || stepsPerRev > 0x3FFF {
+		return fmt.Errorf("Invalid stepper steps per revolution %d, must be between 1 and 16383", stepsPerRev)
+	}
+
+	pins := []int{}
+	for _, pin := range []string{dirPin, stepPin} {
+		p, err := strconv.Atoi(pin)
+		if err != nil {
+			return err
+		}
+		if p < 0 || p >= len(f.Board.Pins()) {
+			return fmt.Errorf("pin %d does not exist", p)
+		}
+		pins = append(pins, p)
+	}
+	return f.Board.StepperConfig(deviceNum, stepType, stepsPerRev, pins[0], pins[1])
+}
+
+// StepperStep moves the stepper deviceNum by steps, up to 2097151, in the
+// direction, 0 for clockwise and 1 for counter clockwise, at speed in
+// rad/sec. The speed is sent in 0.01 rad/sec and clamped to 163.83 rad/sec.
+// A "stepper_done" event with the device number is published when the board
+// reports the move completed.
+func (f *Adaptor) StepperStep(deviceNum int, direction byte, steps int, speed float64) error {
+	if deviceNum < 0 || deviceNum >= client.MaxSteppers {
+		return fmt.Errorf("Invalid stepper device %d, must be between 0 and %d", deviceNum, client.MaxSteppers-1)
+	}
+	if direction > 1 {
+		return fmt.Errorf("Invalid stepper direction %d, must be 0 or 1", direction)
+	}
+	if steps < 0 || steps > 0x1FFFFF {
+		return fmt.Errorf("Invalid stepper steps %d, must be between 0 and 2097151", steps)
+	}
+	if speed < 0 {
+		return fmt.Errorf("Invalid stepper speed %v, must not be negative", speed)
+	}
+
+	s := int(speed * 100)
+	if s > 0x3FFF {
+		s = 0x3FFF
+	}
+	return f.Board.StepperStep(deviceNum, int(direction), steps, s)
+}
+
+// EncoderData is what gets published with the "encoder" event, the position
+// of the encoder and its change since the previous report.
+type EncoderData struct {
+	Encoder  int
+	Position int
+	Delta    int
+}
+
+// EncoderAttach attaches the encoder encoderNum, from 0 to
+// client.MaxEncoders-1, wired to pinA and pinB, and enables the continuous
+// report of its position. Each position reported by the board is published
+// as an "encoder" event with an EncoderData. It requires firmware with the
+// encoder feature, such as ConfigurableFirmata, and pins supporting
+// interrupts.
+func (f *Adaptor) EncoderAttach(encoderNum int, pinA, pinB string) error {
+	if err := f.validateEncoder(encoderNum); err != nil {
+		return err
+	}
+
+	pins := []int{}
+	for _, pin := range []string{pinA, pinB} {
+		p, err := strconv.Atoi(pin)
+		if err != nil {
+			return err
+		}
+		if p < 0 || p >= len(f.Board.Pins()) {
+			return fmt.Errorf("pin %d does not exist", p)
+		}
+		pins = append(pins, p)
+	}
+
+	f.setEncoderPosition(encoderNum, 0)
+	if err := f.Board.EncoderAttach(encoderNum, pins[0], pins[1]); err != nil {
+		return err
+	}
+	return f.Board.EncoderReportAuto(true)
+}
+
+// EncoderReset sets the position of the encoder back to 0.
+func (f *Adaptor) EncoderReset(encoderNum int) error {
+	if err := f.validateEncoder(encoderNum); err != nil {
+		return err
+	}
+	f.setEncoderPosition(encoderNum, 0)
+	return f.Board.EncoderReset(encoderNum)
+}
+
+// EncoderDetach detaches the encoder, its position is no longer reported.
+func (f *Adaptor) EncoderDetach(encoderNum int) error {
+	if err := f.validateEncoder(encoderNum); err != nil {
+		return err
+	}
+	f.mtx.Lock()
+	delete(f.encoders, encoderNum)
+	f.mtx.Unlock()
+	return f.Board.EncoderDetach(encoderNum)
+}
+
+func (f *Adaptor) validateEncoder(encoderNum int) error {
+	if encoderNum < 0 || encoderNum >= client.MaxEncoders {
+		return fmt.Errorf("Invalid encoder %d, must be between 0 and %d", encoderNum, client.MaxEncoders-1)
+	}
+	return nil
+}
+
+func (f *Adaptor) setEncoderPosition(encoderNum int, position int) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.encoders == nil {
+		f.encoders = make(map[int]int)
+	}
+	f.encoders[encoderNum] = position
+}
+
+func (f *Adaptor) publishEncoderPosition(position client.EncoderPosition) {
+	f.mtx.Lock()
+	last := f.encoders[position.Encoder]
+	if f.encoders == nil {
+		f.encoders = make(map[int]int)
+	}
+	f.encoders[position.Encoder] = position.Position
+	f.mtx.Unlock()
+
+	f.Publish("encoder", EncoderData{
+		Encoder:  position.Encoder,
+		Position: position.Position,
+		Delta:    position.Position - last,
+	})
+}
+
 // DigitalWrite writes a value to the pin. Acceptable values are 1 or 0.
 func (f *Adaptor) DigitalWrite(pin string, level byte) (err error) {
 	p, err := strconv.Atoi(pin)
@@ -176,68 +608,399 @@ func (f *Adaptor) DigitalWrite(pin string, level byte) (err error) {
 	}
 
 	if f.Board.Pins()[p].Mode != client.Output {
-		err = f.Board.SetPinMode(p, client.Output)
+		err = f.setPinMode(p, OutputMode)
 		if err != nil {
 			return
 		}
 	}
 
+	if f.DigitalWriteWindow > 0 {
+		f.queueDigitalWrite(p, int(level))
+		return
+	}
+
 	err = f.Board.DigitalWrite(p, int(level))
 	return
 }
 
+// queueDigitalWrite stores the pin value to be written when the current
+// write window elapses.
+func (f *Adaptor) queueDigitalWrite(pin int, level int) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if f.queuedWrites == nil {
+		f.queuedWrites = make(map[int]int)
+	}
+	f.queuedWrites[pin] = level
+
+	if f.flushTimer == nil {
+		f.flushTimer = time.AfterFunc(f.DigitalWriteWindow, func() {
+			if err := f.Flush(); err != nil {
+				f.Publish("Error", err)
+			}
+		})
+	}
+}
+
+// Flush writes all digital values queued since the start of the current
+// write window, using one DIGITAL_MESSAGE per port.
+func (f *Adaptor) Flush() (err error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if f.flushTimer != nil {
+		f.flushTimer.Stop()
+		f.flushTimer = nil
+	}
+
+	if len(f.queuedWrites) == 0 {
+		return
+	}
+	if err = f.Board.DigitalWritePins(f.queuedWrites); err != nil {
+		return
+	}
+	f.queuedWrites = nil
+	return
+}
+
 // DigitalRead retrieves digital value from specified pin.
-// Returns -1 if the response from the board has timed out
+// When reporting is first enabled for the pin, it waits up to ReadTimeout
+// for the board to report the pin value.
 func (f *Adaptor) DigitalRead(pin string) (val int, err error) {
+	return f.digitalRead(pin, InputMode)
+}
+
+// PullupRead retrieves the digital value from the pin, with the internal
+// pull-up resistor of the pin enabled, so that a button wired to ground
+// needs no external resistor. The pin reads 1 while the button is released.
+// When reporting is first enabled for the pin, it waits up to ReadTimeout
+// for the board to report the pin value.
+func (f *Adaptor) PullupRead(pin string) (val int, err error) {
+	return f.digitalRead(pin, PullupMode)
+}
+
+func (f *Adaptor) digitalRead(pin string, mode PinMode) (val int, err error) {
 	p, err := strconv.Atoi(pin)
 	if err != nil {
 		return
 	}
 
-	if f.Board.Pins()[p].Mode != client.Input {
-		if err = f.Board.SetPinMode(p, client.Input); err != nil {
+	if PinMode(f.Board.Pins()[p].Mode) != mode {
+		if err = f.setPinMode(p, mode); err != nil {
 			return
 		}
-		if err = f.Board.ReportDigital(p, 1); err != nil {
+		_, err = f.awaitEvent(fmt.Sprintf("DigitalRead%v", p), func() error {
+			return f.Board.ReportDigital(p, 1)
+		})
+		// the pin keeps its last known value if the board is slow to report
+		if err != nil && err != ErrReadTimeout {
 			return
 		}
-		<-time.After(10 * time.Millisecond)
+		err = nil
 	}
 
 	return f.Board.Pins()[p].Value, nil
 }
 
-// AnalogRead retrieves value from analog pin.
-// Returns -1 if the response from the board has timed out
+// DigitalPortRead returns the values of the 8 pins of the port at once, with
+// the value of pin 8*port in bit 0, as last reported by the board in a
+// single DIGITAL_MESSAGE. All the pins of the port are set to input mode.
+// When reporting is first enabled for the port, it waits up to ReadTimeout
+// for the board to report the port value.
+func (f *Adaptor) DigitalPortRead(port int) (val byte, err error) {
+	pins := f.Board.Pins()
+	if port < 0 || 8*port >= len(pins) {
+		return 0, fmt.Errorf("port %d does not exist", port)
+	}
+
+	first, last := 8*port, 8*port+8
+	if last > len(pins) {
+		last = len(pins)
+	}
+
+	reporting := true
+	for p := first; p < last; p++ {
+		if pins[p].Mode != client.Input {
+			if err = f.setPinMode(p, InputMode); err != nil {
+				return
+			}
+			reporting = false
+		}
+	}
+
+	if !reporting {
+		_, err = f.awaitEvent(fmt.Sprintf("DigitalPortRead%v", port), func() error {
+			return f.Board.ReportDigital(port, 1)
+		})
+		// the pins keep their last known values if the board is slow to report
+		if err != nil && err != ErrReadTimeout {
+			return
+		}
+		err = nil
+	}
+
+	for p := first; p < last; p++ {
+		if pins[p].Value != 0 {
+			val |= 1 << uint(p-first)
+		}
+	}
+	return val, nil
+}
+
+// AnalogRead retrieves value from analog pin, either the analog channel
+// number, such as "0", or its "A0" label, translated through the analog
+// mapping of the board to the digital pin, such as 14 on an Arduino Uno.
+// When reporting is first enabled for the pin, it waits up to ReadTimeout
+// for the board to report the pin value. Until the board answers the analog
+// mapping query, each AnalogRead also queries it, see AnalogMapping.
 func (f *Adaptor) AnalogRead(pin string) (val int, err error) {
-	p, err := strconv.Atoi(pin)
+	channel, err := analogChannel(pin)
 	if err != nil {
 		return
 	}
 
-	p = f.digitalPin(p)
+	p, err := f.digitalPin(channel)
+	if err != nil {
+		return -1, err
+	}
 
 	if f.Board.Pins()[p].Mode != client.Analog {
-		if err = f.Board.SetPinMode(p, client.Analog); err != nil {
+		if err = f.setPinMode(p, AnalogMode); err != nil {
 			return
 		}
 
-		if err = f.Board.ReportAnalog(p, 1); err != nil {
-			return
+		data, err := f.awaitEvent(fmt.Sprintf("AnalogRead%v", channel), func() error {
+			return f.Board.ReportAnalog(channel, 1)
+		})
+		if value, ok := data.(int); ok && err == nil {
+			return value, nil
+		}
+		// the pin keeps its last known value if the board is slow to report
+		if err != nil && err != ErrReadTimeout {
+			return 0, err
 		}
-		<-time.After(10 * time.Millisecond)
 	}
 
 	return f.Board.Pins()[p].Value, nil
 }
 
+// AnalogReadStream enables reporting for the analog pin and sends every
+// value the board reports to the returned channel, until
+// StopAnalogReadStream is called. Values are dropped while the channel is
+// full.
+func (f *Adaptor) AnalogReadStream(pin string) (<-chan int, error) {
+	channel, err := analogChannel(pin)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := f.digitalPin(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if _, ok := f.analogStreams[channel]; ok {
+		return nil, fmt.Errorf("analog pin %d is already streaming", channel)
+	}
+
+	if f.Board.Pins()[p].Mode != client.Analog {
+		if err = f.setPinMode(p, AnalogMode); err != nil {
+			return nil, err
+		}
+	}
+
+	values := make(chan int, 64)
+	stop := make(chan struct{})
+	events := f.Board.Subscribe()
+	name := fmt.Sprintf("AnalogRead%v", channel)
+	go func() {
+		defer close(values)
+		defer f.Board.Unsubscribe(events)
+		for {
+			select {
+			case evt := <-events:
+				if value, ok := evt.Data.(int); ok && evt.Name == name {
+					select {
+					case values <- value:
+					default:
+					}
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	if err = f.Board.ReportAnalog(channel, 1); err != nil {
+		close(stop)
+		return nil, err
+	}
+
+	if f.analogStreams == nil {
+		f.analogStreams = make(map[int]chan struct{})
+	}
+	f.analogStreams[channel] = stop
+	return values, nil
+}
+
+// StopAnalogReadStream disables reporting for the analog pin and closes the
+// channel returned by AnalogReadStream.
+func (f *Adaptor) StopAnalogReadStream(pin string) (err error) {
+	channel, err := analogChannel(pin)
+	if err != nil {
+		return
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	stop, ok := f.analogStreams[channel]
+	if !ok {
+		return fmt.Errorf("analog pin %d is not streaming", channel)
+	}
+	delete(f.analogStreams, channel)
+	close(stop)
+	return f.Board.ReportAnalog(channel, 0)
+}
+
+// awaitEvent subscribes to the named board event, sends the request, and
+// waits up to ReadTimeout for the board to publish the event. The
+// subscription is always removed, even when the event never comes.
+func (f *Adaptor) awaitEvent(name string, request func() error) (interface{}, error) {
+	events := f.Board.Subscribe()
+	defer f.Board.Unsubscribe(events)
+
+	if err := request(); err != nil {
+		return nil, err
+	}
+
+	timeout := time.After(f.ReadTimeout)
+	for {
+		select {
+		case evt := <-events:
+			if evt.Name == name {
+				return evt.Data, nil
+			}
+		case <-timeout:
+			return nil, ErrReadTimeout
+		}
+	}
+}
+
+// FirmwareName queries the board for the name of the firmware it runs,
+// such as "StandardFirmata.ino".
+func (f *Adaptor) FirmwareName() (string, error) {
+	firmware, err := f.queryFirmware()
+	return firmware.Name, err
+}
+
+// FirmwareVersion queries the board for the version of the firmware it runs.
+func (f *Adaptor) FirmwareVersion() (major, minor int, err error) {
+	firmware, err := f.queryFirmware()
+	return firmware.Major, firmware.Minor, err
+}
+
+func (f *Adaptor) queryFirmware() (firmware client.Firmware, err error) {
+	data, err := f.awaitEvent("Firmware", f.Board.FirmwareQuery)
+	if err != nil {
+		return
+	}
+	return data.(client.Firmware), nil
+}
+
 func (f *Adaptor) WriteSysex(data []byte) error {
 	return f.Board.WriteSysex(data)
 }
 
-// digitalPin converts pin number to digital mapping
-func (f *Adaptor) digitalPin(pin int) int {
-	return pin + 14
+// PinState sends PIN_STATE_QUERY to the board and returns the current mode
+// and value of the pin, such as the PWM level of a PWM pin. It allows to
+// recover the state of a board the Adaptor did not initialize.
+func (f *Adaptor) PinState(pin int) (mode byte, value int, err error) {
+	if pin < 0 || pin >= len(f.Board.Pins()) {
+		return 0, 0, fmt.Errorf("pin %d does not exist", pin)
+	}
+
+	data, err := f.awaitEvent(fmt.Sprintf("PinState%v", pin), func() error {
+		return f.Board.PinStateQuery(pin)
+	})
+	if err != nil {
+		return
+	}
+	state := data.(client.Pin)
+	return byte(state.Mode), state.State, nil
+}
+
+// SetSamplingInterval sets how often, in milliseconds, the board reports
+// analog values and continuous I2C reads. The board default is 19ms. The
+// interval is sent as 14 bits, longer intervals are clamped to 16383ms.
+func (f *Adaptor) SetSamplingInterval(ms int) error {
+	if ms < 1 {
+		return fmt.Errorf("Invalid sampling interval %dms, must be at least 1ms", ms)
+	}
+	if ms > 0x3FFF {
+		ms = 0x3FFF
+	}
+	return f.Board.SetSamplingInterval(ms)
+}
+
+// AnalogMapping sends ANALOG_MAPPING_QUERY to the board and returns the
+// digital pin number of each of its analog channels.
+func (f *Adaptor) AnalogMapping() (mapping map[int]int, err error) {
+	if _, err = f.awaitEvent("AnalogMappingQuery", f.Board.AnalogMappingQuery); err != nil {
+		return
+	}
+
+	mapping = make(map[int]int)
+	for pin, p := range f.Board.Pins() {
+		if p.AnalogChannel != 127 {
+			mapping[p.AnalogChannel] = pin
+		}
+	}
+
+	f.mtx.Lock()
+	f.analogMapping = mapping
+	f.mtx.Unlock()
+	return
+}
+
+// analogChannel returns the analog channel of pin, given as the channel
+// number, "0", or its label, "A0".
+func analogChannel(pin string) (int, error) {
+	if strings.HasPrefix(pin, "A") {
+		channel, err := strconv.Atoi(pin[1:])
+		if err != nil {
+			return -1, fmt.Errorf("Invalid analog pin label %q", pin)
+		}
+		return channel, nil
+	}
+	return strconv.Atoi(pin)
+}
+
+// digitalPin converts an analog channel to its digital pin number, using the
+// analog mapping of the board. The mapping is queried until the board
+// answers, boards that do not answer are read as Arduino Unos, with A0 on
+// pin 14.
+func (f *Adaptor) digitalPin(channel int) (int, error) {
+	f.mtx.Lock()
+	mapping := f.analogMapping
+	f.mtx.Unlock()
+	if mapping == nil {
+		mapping, _ = f.AnalogMapping()
+	}
+
+	pin := channel + 14
+	if mapping != nil {
+		var ok bool
+		if pin, ok = mapping[channel]; !ok {
+			return -1, fmt.Errorf("analog pin %d does not exist", channel)
+		}
+	}
+	if channel < 0 || pin >= len(f.Board.Pins()) {
+		return -1, fmt.Errorf("analog pin %d does not exist", channel)
+	}
+	return pin, nil
 }
 
 // GetConnection returns an i2c connection to a device on a specified bus.
@@ -250,6 +1013,33 @@ func (f *Adaptor) GetConnection(address int, bus int) (connection i2c.Connection
 	return NewFirmataI2cConnection(f, address), err
 }
 
+// I2cReadRegister reads size bytes from the register of the i2c device at
+// address, writing the register and reading in a single I2C request.
+func (f *Adaptor) I2cReadRegister(address int, register byte, size uint) ([]byte, error) {
+	buf := make([]byte, size)
+	n, err := NewFirmataI2cConnection(f, address).ReadRegister(register, buf)
+	if n < len(buf) {
+		buf = buf[:n]
+	}
+	return buf, err
+}
+
+// I2cTransfer writes to the i2c device at address and then reads readSize
+// bytes from it. When write is a single register, the read follows it with a
+// repeated start, as sensors usually specify their register reads, otherwise
+// with a stop. No other request to the bus can come between the write and
+// the read.
+func (f *Adaptor) I2cTransfer(address byte, write []byte, readSize uint) ([]byte, error) {
+	buf := make([]byte, readSize)
+	n, err := NewFirmataI2cConnection(f, int(address)).read(buf, func() error {
+		return f.Board.I2cTransfer(int(address), write, int(readSize))
+	})
+	if n < len(buf) {
+		buf = buf[:n]
+	}
+	return buf, err
+}
+
 // GetDefaultBus returns the default i2c bus for this platform
 func (f *Adaptor) GetDefaultBus() int {
 	return 0
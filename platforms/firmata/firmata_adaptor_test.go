@@ -5,10 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	serial "go.bug.st/serial.v1"
 	"gobot.io/x/gobot"
 	"gobot.io/x/gobot/drivers/aio"
 	"gobot.io/x/gobot/drivers/gpio"
@@ -24,6 +28,7 @@ var _ gpio.DigitalWriter = (*Adaptor)(nil)
 var _ aio.AnalogReader = (*Adaptor)(nil)
 var _ gpio.PwmWriter = (*Adaptor)(nil)
 var _ gpio.ServoWriter = (*Adaptor)(nil)
+var _ gpio.ToneWriter = (*Adaptor)(nil)
 var _ i2c.Connector = (*Adaptor)(nil)
 var _ FirmataAdaptor = (*Adaptor)(nil)
 
@@ -54,7 +59,26 @@ func (readWriteCloser) Close() error {
 type mockFirmataBoard struct {
 	disconnectError error
 	gobot.Eventer
-	pins []client.Pin
+	pins          []client.Pin
+	mtx           sync.Mutex
+	digitalWrites []int
+	analogMapping bool
+	interval      int
+	servoConfigs  [][3]int
+	tones         [][3]int
+	steppers      [][]int
+	encoders      [][]int
+	pinModes      [][2]int
+	analogWrites  [][3]int
+
+	i2cRegisterReads [][3]int
+	i2cTransfers     []mockI2cTransfer
+}
+
+type mockI2cTransfer struct {
+	address  int
+	data     []byte
+	numBytes int
 }
 
 func newMockFirmataBoard() *mockFirmataBoard {
@@ -71,23 +95,142 @@ func newMockFirmataBoard() *mockFirmataBoard {
 	return m
 }
 
-func (mockFirmataBoard) Connect(io.ReadWriteCloser) error { return nil }
-func (m mockFirmataBoard) Disconnect() error {
+func (*mockFirmataBoard) Connect(io.ReadWriteCloser) error { return nil }
+func (m *mockFirmataBoard) Disconnect() error {
 	return m.disconnectError
 }
-func (m mockFirmataBoard) Pins() []client.Pin {
+func (m *mockFirmataBoard) Pins() []client.Pin {
 	return m.pins
 }
-func (mockFirmataBoard) AnalogWrite(int, int) error      { return nil }
-func (mockFirmataBoard) SetPinMode(int, int) error       { return nil }
-func (mockFirmataBoard) ReportAnalog(int, int) error     { return nil }
-func (mockFirmataBoard) ReportDigital(int, int) error    { return nil }
-func (mockFirmataBoard) DigitalWrite(int, int) error     { return nil }
-func (mockFirmataBoard) I2cRead(int, int) error          { return nil }
-func (mockFirmataBoard) I2cWrite(int, []byte) error      { return nil }
-func (mockFirmataBoard) I2cConfig(int) error             { return nil }
-func (mockFirmataBoard) ServoConfig(int, int, int) error { return nil }
-func (mockFirmataBoard) WriteSysex(data []byte) error    { return nil }
+func (m *mockFirmataBoard) AnalogWrite(pin int, value int) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.analogWrites = append(m.analogWrites, [3]int{pin, value, 0})
+	return nil
+}
+
+// ExtendedAnalogWrite is recorded in analogWrites with a trailing 1
+func (m *mockFirmataBoard) ExtendedAnalogWrite(pin int, value int) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.analogWrites = append(m.analogWrites, [3]int{pin, value, 1})
+	return nil
+}
+func (m *mockFirmataBoard) SetPinMode(pin int, mode int) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.pinModes = append(m.pinModes, [2]int{pin, mode})
+	return nil
+}
+func (*mockFirmataBoard) ReportAnalog(int, int) error  { return nil }
+func (*mockFirmataBoard) ReportDigital(int, int) error { return nil }
+func (m *mockFirmataBoard) SetSamplingInterval(interval int) error {
+	m.interval = interval
+	return nil
+}
+func (m *mockFirmataBoard) AnalogMappingQuery() error {
+	if m.analogMapping {
+		go m.Publish("AnalogMappingQuery", nil)
+	}
+	return nil
+}
+
+// answerAnalogMapping makes the board answer the analog mapping query, with
+// the channels starting at pin first.
+func (m *mockFirmataBoard) answerAnalogMapping(first int, channels int) {
+	m.analogMapping = true
+	for i := range m.pins {
+		m.pins[i].AnalogChannel = 127
+	}
+	for i := 0; i < channels; i++ {
+		m.pins[first+i].AnalogChannel = i
+	}
+}
+func (m *mockFirmataBoard) PinStateQuery(pin int) error {
+	go m.Publish(fmt.Sprintf("PinState%v", pin), m.pins[pin])
+	return nil
+}
+func (m *mockFirmataBoard) FirmwareQuery() error {
+	go m.Publish("Firmware", client.Firmware{Name: "StandardFirmata.ino", Major: 2, Minor: 5})
+	return nil
+}
+func (m *mockFirmataBoard) DigitalWrite(pin int, value int) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.pins[pin].Value = value
+	m.digitalWrites = append(m.digitalWrites, pin)
+	return nil
+}
+func (m *mockFirmataBoard) DigitalWritePins(values map[int]int) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	pins := []int{}
+	for pin, value := range values {
+		m.pins[pin].Value = value
+		pins = append(pins, pin)
+	}
+	sort.Ints(pins)
+	for i, pin := range pins {
+		// one write per port
+		if i == 0 || pin/8 != pins[i-1]/8 {
+			m.digitalWrites = append(m.digitalWrites, pin)
+		}
+	}
+	return nil
+}
+func (m *mockFirmataBoard) DigitalWrites() []int {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.digitalWrites
+}
+func (*mockFirmataBoard) I2cRead(int, int) error { return nil }
+func (m *mockFirmataBoard) I2cReadRegister(address int, register int, numBytes int) error {
+	m.i2cRegisterReads = append(m.i2cRegisterReads, [3]int{address, register, numBytes})
+	return nil
+}
+func (*mockFirmataBoard) I2cWrite(int, []byte) error { return nil }
+func (m *mockFirmataBoard) I2cTransfer(address int, data []byte, numBytes int) error {
+	m.i2cTransfers = append(m.i2cTransfers, mockI2cTransfer{address, data, numBytes})
+	return nil
+}
+func (*mockFirmataBoard) I2cConfig(int) error { return nil }
+func (m *mockFirmataBoard) ServoConfig(pin int, max int, min int) error {
+	m.servoConfigs = append(m.servoConfigs, [3]int{pin, min, max})
+	return nil
+}
+func (m *mockFirmataBoard) Tone(pin int, frequency int, duration int) error {
+	m.tones = append(m.tones, [3]int{pin, frequency, duration})
+	return nil
+}
+func (m *mockFirmataBoard) NoTone(pin int) error {
+	m.tones = append(m.tones, [3]int{pin, 0, 0})
+	return nil
+}
+func (m *mockFirmataBoard) StepperConfig(deviceNum int, stepInterface int, stepsPerRev int, dirPin int, stepPin int) error {
+	m.steppers = append(m.steppers, []int{deviceNum, stepInterface, stepsPerRev, dirPin, stepPin})
+	return nil
+}
+func (m *mockFirmataBoard) StepperStep(deviceNum int, direction int, steps int, speed int) error {
+	m.steppers = append(m.steppers, []int{deviceNum, direction, steps, speed})
+	return nil
+}
+func (m *mockFirmataBoard) EncoderAttach(encoderNum int, pinA int, pinB int) error {
+	m.encoders = append(m.encoders, []int{encoderNum, pinA, pinB})
+	return nil
+}
+func (m *mockFirmataBoard) EncoderReset(encoderNum int) error {
+	m.encoders = append(m.encoders, []int{encoderNum})
+	return nil
+}
+func (m *mockFirmataBoard) EncoderDetach(encoderNum int) error {
+	m.encoders = append(m.encoders, []int{-encoderNum})
+	return nil
+}
+func (m *mockFirmataBoard) EncoderReportAuto(enable bool) error {
+	m.encoders = append(m.encoders, []int{})
+	return nil
+}
+func (*mockFirmataBoard) WriteSysex(data []byte) error { return nil }
 
 func initTestAdaptor() *Adaptor {
 	a := NewAdaptor("/dev/null")
@@ -102,6 +245,28 @@ func initTestAdaptor() *Adaptor {
 func TestAdaptor(t *testing.T) {
 	a := initTestAdaptor()
 	gobottest.Assert(t, a.Port(), "/dev/null")
+	gobottest.Assert(t, a.Baud(), 57600)
+
+	a = NewAdaptor("/dev/null", 115200)
+	gobottest.Assert(t, a.Port(), "/dev/null")
+	gobottest.Assert(t, a.Baud(), 115200)
+}
+
+func TestAdaptorSerialMode(t *testing.T) {
+	a := NewAdaptor("/dev/null")
+	gobottest.Assert(t, a.serialMode(), &serial.Mode{BaudRate: 57600})
+
+	a = NewAdaptor("/dev/null", &serial.Mode{Parity: serial.EvenParity, StopBits: serial.TwoStopBits})
+	gobottest.Assert(t, a.Baud(), 57600)
+	gobottest.Assert(t, a.serialMode(), &serial.Mode{BaudRate: 57600, Parity: serial.EvenParity, StopBits: serial.TwoStopBits})
+
+	a = NewAdaptor("/dev/null", &serial.Mode{BaudRate: 9600, DataBits: 7})
+	gobottest.Assert(t, a.Baud(), 9600)
+	gobottest.Assert(t, a.serialMode(), &serial.Mode{BaudRate: 9600, DataBits: 7})
+
+	// the baud rate given as an int wins over the one of the mode before it
+	a = NewAdaptor("/dev/null", &serial.Mode{BaudRate: 9600}, 115200)
+	gobottest.Assert(t, a.serialMode(), &serial.Mode{BaudRate: 115200})
 }
 
 func TestAdaptorFinalize(t *testing.T) {
@@ -138,9 +303,49 @@ func TestAdaptorConnect(t *testing.T) {
 	gobottest.Assert(t, a.Disconnect(), nil)
 }
 
+// writeRecorder records the data the client writes to the board, reads
+// fail as if the board was unplugged
+type writeRecorder struct {
+	mtx     sync.Mutex
+	written []byte
+}
+
+func (w *writeRecorder) Read(b []byte) (int, error) { return 0, io.EOF }
+func (w *writeRecorder) Close() error               { return nil }
+
+func (w *writeRecorder) Write(p []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.written = append(w.written, p...)
+	return len(p), nil
+}
+
+func (w *writeRecorder) Written() []byte {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.written
+}
+
+func TestAdaptorResetOnConnect(t *testing.T) {
+	gobottest.Assert(t, NewAdaptor("/dev/null").ResetOnConnect, true)
+
+	for _, reset := range []bool{false, true} {
+		conn := &writeRecorder{}
+		a := NewAdaptor(conn)
+		a.Board.(*client.Client).ConnectTimeout = 10 * time.Millisecond
+		a.ResetOnConnect = reset
+
+		gobottest.Refute(t, a.Connect(), nil)
+		gobottest.Assert(t, conn.Written()[0] == client.SystemReset, reset)
+	}
+}
+
 func TestAdaptorServoWrite(t *testing.T) {
 	a := initTestAdaptor()
-	gobottest.Assert(t, a.ServoWrite("1", 50), nil)
+	gobottest.Assert(t, a.ServoWrite("1", 0), nil)
+	gobottest.Assert(t, a.ServoWrite("1", 90), nil)
+	gobottest.Assert(t, a.ServoWrite("1", 180), nil)
+	gobottest.Assert(t, a.ServoWrite("1", 200), errors.New("Invalid servo angle 200, must be between 0 and 180"))
 }
 
 func TestAdaptorServoWriteBadPin(t *testing.T) {
@@ -153,16 +358,174 @@ func TestAdaptorPwmWrite(t *testing.T) {
 	gobottest.Assert(t, a.PwmWrite("1", 50), nil)
 }
 
+func TestAdaptorPwmWrite16(t *testing.T) {
+	a := initTestAdaptor()
+	board := a.Board.(*mockFirmataBoard)
+	board.pins[3].Resolutions = map[int]int{client.Pwm: 12}
+	board.pins[20].Resolutions = map[int]int{client.Pwm: 8}
+
+	gobottest.Assert(t, a.PwmWrite16("3", 200), nil)
+	gobottest.Assert(t, a.PwmWrite16("3", 4095), nil)
+	gobottest.Assert(t, a.PwmWrite16("20", 128), nil)
+	gobottest.Assert(t, board.analogWrites, [][3]int{{3, 200, 0}, {3, 4095, 1}, {20, 128, 1}})
+
+	gobottest.Assert(t, a.PwmWrite16("3", 4096), errors.New("Invalid PWM level 4096, must be between 0 and 4095"))
+	gobottest.Assert(t, a.PwmWrite16("20", 256), errors.New("Invalid PWM level 256, must be between 0 and 255"))
+	gobottest.Assert(t, a.PwmWrite16("100", 1), errors.New("pin 100 does not exist"))
+	gobottest.Refute(t, a.PwmWrite16("xyz", 1), nil)
+}
+
 func TestAdaptorPwmWriteBadPin(t *testing.T) {
 	a := initTestAdaptor()
 	gobottest.Refute(t, a.PwmWrite("xyz", 50), nil)
 }
 
+func TestAdaptorTone(t *testing.T) {
+	a := initTestAdaptor()
+	board := a.Board.(*mockFirmataBoard)
+	gobottest.Assert(t, a.Tone("8", 440, 1000), nil)
+	gobottest.Assert(t, a.NoTone("8"), nil)
+	gobottest.Assert(t, board.tones, [][3]int{{8, 440, 1000}, {8, 0, 0}})
+
+	gobottest.Assert(t, a.Tone("8", 0, 1000), errors.New("Invalid tone frequency 0Hz, must be between 1 and 16383Hz"))
+	gobottest.Assert(t, a.Tone("8", 440, -1), errors.New("Invalid tone duration -1ms, must be between 0 and 16383ms"))
+	gobottest.Assert(t, a.Tone("100", 440, 1000), errors.New("pin 100 does not exist"))
+	gobottest.Assert(t, a.NoTone("100"), errors.New("pin 100 does not exist"))
+	gobottest.Refute(t, a.Tone("a", 440, 1000), nil)
+	gobottest.Assert(t, len(board.tones), 2)
+}
+
+func TestAdaptorToneUnsupported(t *testing.T) {
+	a := initTestAdaptor()
+	a.Board.Pins()[8].SupportedModes = []int{client.Input, client.Output}
+	gobottest.Assert(t, a.Tone("8", 440, 1000), ErrToneUnsupported)
+
+	a.Board.Pins()[9].SupportedModes = []int{client.Input, client.Output, client.Tone}
+	gobottest.Assert(t, a.Tone("8", 440, 1000), errors.New("pin 8 does not support tone"))
+	gobottest.Assert(t, a.Tone("9", 440, 1000), nil)
+}
+
+func TestAdaptorStepper(t *testing.T) {
+	a := initTestAdaptor()
+	board := a.Board.(*mockFirmataBoard)
+	gobottest.Assert(t, a.StepperConfig(0, client.StepperDriver, 200, "2", "3"), nil)
+	gobottest.Assert(t, a.StepperStep(0, 1, 400, 2.5), nil)
+	gobottest.Assert(t, a.StepperStep(0, 0, 400, 1000), nil)
+	gobottest.Assert(t, board.steppers, [][]int{{0, 1, 200, 2, 3}, {0, 1, 400, 250}, {0, 0, 400, 0x3FFF}})
+
+	gobottest.Assert(t, a.StepperConfig(6, client.StepperDriver, 200, "2", "3"),
+		errors.New("Invalid stepper device 6, must be between 0 and 5"))
+	gobottest.Assert(t, a.StepperConfig(0, 4, 200, "2", "3"), errors.New("Invalid stepper type 4"))
+	gobottest.Assert(t, a.StepperConfig(0, client.StepperDriver, 0, "2", "3"),
+		errors.New("Invalid stepper steps per revolution 0, must be between 1 and 16383"))
+	gobottest.Assert(t, a.StepperConfig(0, client.StepperDriver, 200, "2", "100"), errors.New("pin 100 does not exist"))
+	gobottest.Assert(t, a.StepperStep(-1, 0, 400, 1), errors.New("Invalid stepper device -1, must be between 0 and 5"))
+	gobottest.Assert(t, a.StepperStep(0, 2, 400, 1), errors.New("Invalid stepper direction 2, must be 0 or 1"))
+	gobottest.Assert(t, a.StepperStep(0, 0, 0x200000, 1), errors.New("Invalid stepper steps 2097152, must be between 0 and 2097151"))
+	gobottest.Assert(t, a.StepperStep(0, 0, 400, -1), errors.New("Invalid stepper speed -1, must not be negative"))
+	gobottest.Assert(t, len(board.steppers), 3)
+}
+
+func TestAdaptorStepperDone(t *testing.T) {
+	a := initTestAdaptor()
+	sem := make(chan interface{})
+	a.On("stepper_done", func(data interface{}) {
+		sem <- data
+	})
+
+	a.Board.Publish("StepperDone", 2)
+	select {
+	case data := <-sem:
+		gobottest.Assert(t, data, 2)
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("stepper_done was not published")
+	}
+}
+
+func TestAdaptorEncoder(t *testing.T) {
+	a := initTestAdaptor()
+	board := a.Board.(*mockFirmataBoard)
+	gobottest.Assert(t, a.EncoderAttach(2, "2", "3"), nil)
+	gobottest.Assert(t, a.EncoderReset(2), nil)
+	gobottest.Assert(t, a.EncoderDetach(2), nil)
+	gobottest.Assert(t, board.encoders, [][]int{{2, 2, 3}, {}, {2}, {-2}})
+
+	gobottest.Assert(t, a.EncoderAttach(5, "2", "3"), errors.New("Invalid encoder 5, must be between 0 and 4"))
+	gobottest.Assert(t, a.EncoderAttach(0, "2", "100"), errors.New("pin 100 does not exist"))
+	gobottest.Assert(t, a.EncoderReset(-1), errors.New("Invalid encoder -1, must be between 0 and 4"))
+	gobottest.Assert(t, a.EncoderDetach(5), errors.New("Invalid encoder 5, must be between 0 and 4"))
+	gobottest.Assert(t, len(board.encoders), 4)
+}
+
+func TestAdaptorEncoderEvents(t *testing.T) {
+	a := initTestAdaptor()
+	a.EncoderAttach(1, "2", "3")
+	events := make(chan EncoderData, 3)
+	a.On("encoder", func(data interface{}) {
+		events <- data.(EncoderData)
+	})
+
+	expected := []EncoderData{{1, 10, 10}, {1, -5, -15}, {1, 0, 5}}
+	for _, position := range []int{10, -5, 0} {
+		a.Board.Publish("EncoderPosition", client.EncoderPosition{Encoder: 1, Position: position})
+		select {
+		case data := <-events:
+			gobottest.Assert(t, data, expected[0])
+			expected = expected[1:]
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("encoder was not published")
+		}
+	}
+}
+
 func TestAdaptorDigitalWrite(t *testing.T) {
 	a := initTestAdaptor()
 	gobottest.Assert(t, a.DigitalWrite("1", 1), nil)
 }
 
+func TestAdaptorDigitalWriteCoalescing(t *testing.T) {
+	a := initTestAdaptor()
+	board := a.Board.(*mockFirmataBoard)
+	a.DigitalWriteWindow = time.Hour
+
+	for _, pin := range []string{"2", "3", "4", "9", "10"} {
+		gobottest.Assert(t, a.DigitalWrite(pin, 1), nil)
+	}
+	gobottest.Assert(t, len(board.DigitalWrites()), 0)
+	gobottest.Assert(t, board.pins[3].Value, 0)
+
+	gobottest.Assert(t, a.Flush(), nil)
+	gobottest.Assert(t, board.pins[3].Value, 1)
+	gobottest.Assert(t, len(board.DigitalWrites()), 2)
+	gobottest.Assert(t, board.DigitalWrites()[0]/8, 0)
+	gobottest.Assert(t, board.DigitalWrites()[1]/8, 1)
+
+	// nothing left to write
+	gobottest.Assert(t, a.Flush(), nil)
+	gobottest.Assert(t, len(board.DigitalWrites()), 2)
+
+	a.DigitalWriteWindow = 10 * time.Millisecond
+	gobottest.Assert(t, a.DigitalWrite("5", 1), nil)
+	gobottest.Assert(t, a.DigitalWrite("6", 0), nil)
+	<-time.After(50 * time.Millisecond)
+	gobottest.Assert(t, len(board.DigitalWrites()), 3)
+}
+
+func TestAdaptorFinalizeFlushesDigitalWrites(t *testing.T) {
+	a := initTestAdaptor()
+	board := a.Board.(*mockFirmataBoard)
+	a.DigitalWriteWindow = 10 * time.Millisecond
+
+	gobottest.Assert(t, a.DigitalWrite("2", 1), nil)
+	gobottest.Assert(t, a.DigitalWrite("9", 1), nil)
+	gobottest.Assert(t, a.Finalize(), nil)
+	gobottest.Assert(t, len(board.DigitalWrites()), 2)
+
+	// the write window timer was stopped
+	<-time.After(50 * time.Millisecond)
+	gobottest.Assert(t, len(board.DigitalWrites()), 2)
+}
+
 func TestAdaptorDigitalWriteBadPin(t *testing.T) {
 	a := initTestAdaptor()
 	gobottest.Refute(t, a.DigitalWrite("xyz", 50), nil)
@@ -179,6 +542,40 @@ func TestAdaptorDigitalRead(t *testing.T) {
 	gobottest.Assert(t, val, 0)
 }
 
+func TestAdaptorPullupRead(t *testing.T) {
+	a := initTestAdaptor()
+	val, err := a.PullupRead("1")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, 1)
+	gobottest.Assert(t, a.Board.(*mockFirmataBoard).pinModes, [][2]int{{1, 0x0B}})
+
+	_, err = a.PullupRead("xyz")
+	gobottest.Refute(t, err, nil)
+}
+
+func TestAdaptorDigitalPortRead(t *testing.T) {
+	a := initTestAdaptor()
+	a.Board.Pins()[9].Value = 1
+	a.Board.Pins()[15].Value = 1
+
+	val, err := a.DigitalPortRead(0)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, byte(0x02))
+
+	val, err = a.DigitalPortRead(1)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, byte(0x82))
+}
+
+func TestAdaptorDigitalPortReadBadPort(t *testing.T) {
+	a := initTestAdaptor()
+	_, err := a.DigitalPortRead(13)
+	gobottest.Assert(t, err, errors.New("port 13 does not exist"))
+
+	_, err = a.DigitalPortRead(-1)
+	gobottest.Refute(t, err, nil)
+}
+
 func TestAdaptorDigitalReadBadPin(t *testing.T) {
 	a := initTestAdaptor()
 	_, err := a.DigitalRead("xyz")
@@ -196,12 +593,229 @@ func TestAdaptorAnalogRead(t *testing.T) {
 	gobottest.Assert(t, val, 0)
 }
 
+func TestAdaptorAnalogReadLabel(t *testing.T) {
+	a := initTestAdaptor()
+	board := a.Board.(*mockFirmataBoard)
+	board.pins[14].Value = 512
+
+	val, err := a.AnalogRead("A0")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, 512)
+
+	// labels are translated through the mapping of the board, here a Mega
+	board.pins[54].Value = 321
+	board.answerAnalogMapping(54, 16)
+	val, err = a.AnalogRead("A0")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, 321)
+
+	// numbers are analog channels, not digital pins
+	_, err = a.AnalogRead("14")
+	gobottest.Assert(t, err, nil)
+	_, err = a.AnalogRead("16")
+	gobottest.Assert(t, err, errors.New("analog pin 16 does not exist"))
+	_, err = a.AnalogRead("A16")
+	gobottest.Assert(t, err, errors.New("analog pin 16 does not exist"))
+	_, err = a.AnalogRead("Ax")
+	gobottest.Assert(t, err, errors.New("Invalid analog pin label \"Ax\""))
+}
+
+func TestAdaptorPinState(t *testing.T) {
+	a := initTestAdaptor()
+	a.Board.Pins()[3].Mode = client.Pwm
+	a.Board.Pins()[3].State = 200
+
+	mode, value, err := a.PinState(3)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, mode, byte(client.Pwm))
+	gobottest.Assert(t, value, 200)
+
+	_, _, err = a.PinState(100)
+	gobottest.Assert(t, err, errors.New("pin 100 does not exist"))
+}
+
+func TestAdaptorSetSamplingInterval(t *testing.T) {
+	a := initTestAdaptor()
+	gobottest.Assert(t, a.SetSamplingInterval(1000), nil)
+	gobottest.Assert(t, a.Board.(*mockFirmataBoard).interval, 1000)
+
+	gobottest.Assert(t, a.SetSamplingInterval(100000), nil)
+	gobottest.Assert(t, a.Board.(*mockFirmataBoard).interval, 16383)
+
+	gobottest.Assert(t, a.SetSamplingInterval(0), errors.New("Invalid sampling interval 0ms, must be at least 1ms"))
+}
+
+func TestAdaptorAnalogMapping(t *testing.T) {
+	a := initTestAdaptor()
+	_, err := a.AnalogMapping()
+	gobottest.Assert(t, err, ErrReadTimeout)
+
+	// an Arduino Mega, with A0 on pin 54
+	board := a.Board.(*mockFirmataBoard)
+	board.answerAnalogMapping(54, 16)
+	board.pins[55].Value = 321
+
+	mapping, err := a.AnalogMapping()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, len(mapping), 16)
+	gobottest.Assert(t, mapping[0], 54)
+
+	val, err := a.AnalogRead("1")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, 321)
+
+	_, err = a.AnalogRead("16")
+	gobottest.Assert(t, err, errors.New("analog pin 16 does not exist"))
+}
+
+func TestAdaptorAnalogReadAfterMappingTimeout(t *testing.T) {
+	a := initTestAdaptor()
+	board := a.Board.(*mockFirmataBoard)
+	board.pins[55].Value = 321
+
+	val, err := a.AnalogRead("1")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, 133)
+
+	// the mapping is queried again once the board answers
+	board.answerAnalogMapping(54, 16)
+	val, err = a.AnalogRead("1")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, 321)
+}
+
+func TestAdaptorAnalogReadStream(t *testing.T) {
+	a := initTestAdaptor()
+	values, err := a.AnalogReadStream("1")
+	gobottest.Assert(t, err, nil)
+
+	_, err = a.AnalogReadStream("1")
+	gobottest.Assert(t, err, errors.New("analog pin 1 is already streaming"))
+
+	for _, v := range []int{10, 20, 30} {
+		a.Board.Publish("AnalogRead1", v)
+	}
+	a.Board.Publish("AnalogRead2", 99)
+	for _, v := range []int{10, 20, 30} {
+		select {
+		case value := <-values:
+			gobottest.Assert(t, value, v)
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("analog value %v was not streamed", v)
+		}
+	}
+
+	gobottest.Assert(t, a.StopAnalogReadStream("1"), nil)
+	select {
+	case _, ok := <-values:
+		gobottest.Assert(t, ok, false)
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("stream channel was not closed")
+	}
+	gobottest.Assert(t, a.StopAnalogReadStream("1"), errors.New("analog pin 1 is not streaming"))
+
+	_, err = a.AnalogReadStream("xyz")
+	gobottest.Refute(t, err, nil)
+}
+
 func TestAdaptorAnalogReadBadPin(t *testing.T) {
 	a := initTestAdaptor()
 	_, err := a.AnalogRead("xyz")
 	gobottest.Refute(t, err, nil)
 }
 
+func TestAdaptorReadTimeout(t *testing.T) {
+	a := NewAdaptor("/dev/null", 50*time.Millisecond)
+	gobottest.Assert(t, a.ReadTimeout, 50*time.Millisecond)
+
+	// the board replies after 20ms, past the default timeout
+	reply := func(a *Adaptor, event string, data interface{}) {
+		go func() {
+			<-time.After(20 * time.Millisecond)
+			a.Board.Publish(event, data)
+		}()
+	}
+
+	a = initTestAdaptor()
+	reply(a, "AnalogRead1", nil)
+	val, _ := a.AnalogRead("1")
+	gobottest.Assert(t, val, 133)
+
+	a.I2cReadTimeout = 10 * time.Millisecond
+	con, _ := a.GetConnection(0, 0)
+	reply(a, "I2cReply", client.I2cReply{Data: []byte{100}})
+	_, err := con.Read([]byte{0})
+	gobottest.Assert(t, err, ErrReadTimeout)
+
+	a = initTestAdaptor()
+	a.ReadTimeout = 100 * time.Millisecond
+	a.I2cReadTimeout = 100 * time.Millisecond
+	a.Board.(*mockFirmataBoard).answerAnalogMapping(14, 6)
+	reply(a, "AnalogRead1", 200)
+	start := time.Now()
+	val, _ = a.AnalogRead("1")
+	gobottest.Assert(t, val, 200)
+	gobottest.Assert(t, time.Since(start) < 100*time.Millisecond, true)
+
+	con, _ = a.GetConnection(0, 0)
+	reply(a, "I2cReply", client.I2cReply{Data: []byte{100}})
+	response := []byte{0}
+	_, err = con.Read(response)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, response, []byte{100})
+}
+
+func TestAdaptorI2cReadTimeout(t *testing.T) {
+	a := NewAdaptor("/dev/null")
+	gobottest.Assert(t, a.I2cReadTimeout, time.Second)
+
+	// I2C reads do not use the shorter ReadTimeout
+	a = initTestAdaptor()
+	go func() {
+		<-time.After(50 * time.Millisecond)
+		a.Board.Publish("I2cReply", client.I2cReply{Data: []byte{100}})
+	}()
+	con, _ := a.GetConnection(0, 0)
+	response := []byte{0}
+	_, err := con.Read(response)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, response, []byte{100})
+}
+
+func TestAdaptorReadTimeoutsDoNotLeak(t *testing.T) {
+	a := initTestAdaptor()
+	a.ReadTimeout = time.Millisecond
+	a.I2cReadTimeout = time.Millisecond
+	con, _ := a.GetConnection(0, 0)
+	before := runtime.NumGoroutine()
+
+	// the mock board never reports, every read times out
+	for i := 0; i < 1000; i++ {
+		_, err := a.DigitalRead("1")
+		gobottest.Assert(t, err, nil)
+		_, err = a.AnalogRead("1")
+		gobottest.Assert(t, err, nil)
+		_, err = con.Read([]byte{0})
+		gobottest.Assert(t, err, ErrReadTimeout)
+	}
+	_, err := a.AnalogMapping()
+	gobottest.Assert(t, err, ErrReadTimeout)
+
+	gobottest.AssertMaxGoroutines(t, before)
+}
+
+func TestAdaptorFirmware(t *testing.T) {
+	a := initTestAdaptor()
+	name, err := a.FirmwareName()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, name, "StandardFirmata.ino")
+
+	major, minor, err := a.FirmwareVersion()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, major, 2)
+	gobottest.Assert(t, minor, 5)
+}
+
 func TestAdaptorI2cStart(t *testing.T) {
 	a := initTestAdaptor()
 	i2c, err := a.GetConnection(0, 0)
@@ -228,6 +842,80 @@ func TestAdaptorI2cRead(t *testing.T) {
 	gobottest.Assert(t, response, i)
 }
 
+func TestAdaptorI2cReadReplyTooLong(t *testing.T) {
+	a := initTestAdaptor()
+	go func() {
+		<-time.After(10 * time.Millisecond)
+		a.Board.Publish("I2cReply", client.I2cReply{Data: []byte{1, 2, 3}})
+	}()
+
+	con, _ := a.GetConnection(0, 0)
+	response := []byte{0, 0}
+	n, err := con.Read(response)
+	gobottest.Assert(t, err, errors.New("Invalid I2C reply of 3 bytes, must be at most 2"))
+	gobottest.Assert(t, n, 2)
+	gobottest.Assert(t, response, []byte{1, 2})
+}
+
+func TestAdaptorI2cReadRegister(t *testing.T) {
+	a := initTestAdaptor()
+	go func() {
+		<-time.After(10 * time.Millisecond)
+		a.Board.Publish("I2cReply", client.I2cReply{Address: 0x68, Register: 0x3B, Data: []byte{1, 2}})
+	}()
+
+	data, err := a.I2cReadRegister(0x68, 0x3B, 2)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, data, []byte{1, 2})
+	gobottest.Assert(t, a.Board.(*mockFirmataBoard).i2cRegisterReads, [][3]int{{0x68, 0x3B, 2}})
+
+	a.I2cReadTimeout = 10 * time.Millisecond
+	data, err = a.I2cReadRegister(0x68, 0x3B, 2)
+	gobottest.Assert(t, err, ErrReadTimeout)
+	gobottest.Assert(t, data, []byte{})
+}
+
+func TestAdaptorI2cTransfer(t *testing.T) {
+	a := initTestAdaptor()
+	go func() {
+		<-time.After(10 * time.Millisecond)
+		a.Board.Publish("I2cReply", client.I2cReply{Address: 0x68, Data: []byte{1, 2}})
+	}()
+
+	data, err := a.I2cTransfer(0x68, []byte{0x3B}, 2)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, data, []byte{1, 2})
+	gobottest.Assert(t, a.Board.(*mockFirmataBoard).i2cTransfers, []mockI2cTransfer{{0x68, []byte{0x3B}, 2}})
+
+	a.I2cReadTimeout = 10 * time.Millisecond
+	data, err = a.I2cTransfer(0x68, []byte{0x3B}, 2)
+	gobottest.Assert(t, err, ErrReadTimeout)
+	gobottest.Assert(t, data, []byte{})
+}
+
+func TestAdaptorI2cReadConcurrent(t *testing.T) {
+	a := initTestAdaptor()
+	go func() {
+		<-time.After(10 * time.Millisecond)
+		a.Board.Publish("I2cReply", client.I2cReply{Address: 0x20, Data: []byte{2}})
+		a.Board.Publish("I2cReply", client.I2cReply{Address: 0x10, Data: []byte{1}})
+	}()
+
+	var wg sync.WaitGroup
+	for _, address := range []int{0x10, 0x20} {
+		con, _ := a.GetConnection(address, 0)
+		wg.Add(1)
+		go func(address int) {
+			defer wg.Done()
+			response := []byte{0}
+			_, err := con.Read(response)
+			gobottest.Assert(t, err, nil)
+			gobottest.Assert(t, response, []byte{byte(address >> 4)})
+		}(address)
+	}
+	wg.Wait()
+}
+
 func TestAdaptorI2cReadByte(t *testing.T) {
 	a := initTestAdaptor()
 	i := []byte{100}
@@ -334,6 +1022,30 @@ func TestServoConfig(t *testing.T) {
 	// test atoi error
 	err = a.ServoConfig("a", 0, 0)
 	gobottest.Assert(t, true, strings.Contains(fmt.Sprintf("%v", err), "invalid syntax"))
+
+	err = a.ServoConfig("9", 2500, 500)
+	gobottest.Assert(t, err, errors.New("Invalid servo pulse width range 2500-500"))
+
+	err = a.ServoConfig("100", 500, 2500)
+	gobottest.Assert(t, err, errors.New("pin 100 does not exist"))
+	gobottest.Assert(t, len(a.Board.(*mockFirmataBoard).servoConfigs), 1)
+}
+
+func TestServoConfigServoWrite(t *testing.T) {
+	a := initTestAdaptor()
+	board := a.Board.(*mockFirmataBoard)
+	gobottest.Assert(t, a.ServoConfig("9", 500, 2500), nil)
+	gobottest.Assert(t, board.servoConfigs, [][3]int{{9, 500, 2500}})
+	gobottest.Assert(t, board.pins[9].Mode, client.Servo)
+
+	// the pin is already configured as a servo
+	gobottest.Assert(t, a.ServoWrite("9", 90), nil)
+	gobottest.Assert(t, len(board.servoConfigs), 1)
+
+	// the range is sent again when switching back to servo mode
+	board.pins[9].Mode = client.Output
+	gobottest.Assert(t, a.ServoWrite("9", 90), nil)
+	gobottest.Assert(t, board.servoConfigs, [][3]int{{9, 500, 2500}, {9, 500, 2500}})
 }
 
 func TestDefaultBus(t *testing.T) {
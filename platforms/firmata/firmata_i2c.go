@@ -1,6 +1,9 @@
 package firmata
 
 import (
+	"fmt"
+	"time"
+
 	//	"gobot.io/x/gobot/drivers/i2c"
 	"gobot.io/x/gobot/platforms/firmata/client"
 )
@@ -17,24 +20,50 @@ func NewFirmataI2cConnection(adaptor *Adaptor, address int) (connection *firmata
 }
 
 // Read tries to read a full buffer from the i2c device.
-// Returns an empty array if the response from the board has timed out.
+// Returns ErrReadTimeout if the board does not reply within the
+// I2cReadTimeout of the adaptor. Replies from other devices are ignored, so that devices
+// at different addresses can be read concurrently.
 func (c *firmataI2cConnection) Read(b []byte) (read int, err error) {
-	ret := make(chan []byte)
-
-	if err = c.adaptor.Board.I2cRead(c.address, len(b)); err != nil {
-		return
-	}
+	return c.read(b, func() error {
+		return c.adaptor.Board.I2cRead(c.address, len(b))
+	})
+}
 
-	c.adaptor.Board.Once(c.adaptor.Board.Event("I2cReply"), func(data interface{}) {
-		ret <- data.(client.I2cReply).Data
+// ReadRegister reads a full buffer from the register of the i2c device, in
+// a single I2C request.
+func (c *firmataI2cConnection) ReadRegister(reg uint8, b []byte) (read int, err error) {
+	return c.read(b, func() error {
+		return c.adaptor.Board.I2cReadRegister(c.address, int(reg), len(b))
 	})
+}
 
-	result := <-ret
-	copy(b, result)
+// read sends the I2C request and waits for the reply of the device. It
+// returns an error when the reply does not fit in b.
+func (c *firmataI2cConnection) read(b []byte, request func() error) (read int, err error) {
+	events := c.adaptor.Board.Subscribe()
+	defer c.adaptor.Board.Unsubscribe(events)
 
-	read = len(result)
+	if err = request(); err != nil {
+		return
+	}
 
-	return
+	timeout := time.After(c.adaptor.I2cReadTimeout)
+	for {
+		select {
+		case evt := <-events:
+			reply, ok := evt.Data.(client.I2cReply)
+			if evt.Name != "I2cReply" || !ok || reply.Address != c.address {
+				continue
+			}
+			read = copy(b, reply.Data)
+			if len(reply.Data) > len(b) {
+				err = fmt.Errorf("Invalid I2C reply of %d bytes, must be at most %d", len(reply.Data), len(b))
+			}
+			return
+		case <-timeout:
+			return 0, ErrReadTimeout
+		}
+	}
 }
 
 func (c *firmataI2cConnection) Write(data []byte) (written int, err error) {
@@ -68,19 +97,17 @@ func (c *firmataI2cConnection) ReadByte() (val byte, err error) {
 }
 
 func (c *firmataI2cConnection) ReadByteData(reg uint8) (val uint8, err error) {
-	if err = c.WriteByte(reg); err != nil {
+	buf := []byte{0}
+	if _, err = c.ReadRegister(reg, buf); err != nil {
 		return
 	}
-	return c.ReadByte()
+	val = buf[0]
+	return
 }
 
 func (c *firmataI2cConnection) ReadWordData(reg uint8) (val uint16, err error) {
-	if err = c.WriteByte(reg); err != nil {
-		return
-	}
-
 	buf := []byte{0, 0}
-	if _, err = c.Read(buf); err != nil {
+	if _, err = c.ReadRegister(reg, buf); err != nil {
 		return
 	}
 	low, high := buf[0], buf[1]
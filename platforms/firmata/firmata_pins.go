@@ -0,0 +1,90 @@
+package firmata
+
+import (
+	"fmt"
+
+	"gobot.io/x/gobot/platforms/firmata/client"
+)
+
+// PinMode is the mode of a pin on a Firmata board
+type PinMode int
+
+// Pin modes supported by the Adaptor
+const (
+	InputMode  PinMode = client.Input
+	OutputMode PinMode = client.Output
+	AnalogMode PinMode = client.Analog
+	PwmMode    PinMode = client.Pwm
+	ServoMode  PinMode = client.Servo
+	PullupMode PinMode = client.Pullup
+	ToneMode   PinMode = client.Tone
+)
+
+func (m PinMode) String() string {
+	switch m {
+	case InputMode:
+		return "input"
+	case OutputMode:
+		return "output"
+	case AnalogMode:
+		return "analog"
+	case PwmMode:
+		return "PWM"
+	case ServoMode:
+		return "servo"
+	case PullupMode:
+		return "pullup"
+	case ToneMode:
+		return "tone"
+	}
+	return fmt.Sprintf("mode %d", int(m))
+}
+
+// Capabilities returns the modes supported by each pin of the board, as
+// reported by the board in its CAPABILITY_RESPONSE when connecting.
+func (f *Adaptor) Capabilities() map[int][]PinMode {
+	capabilities := make(map[int][]PinMode)
+	for pin, p := range f.Board.Pins() {
+		modes := []PinMode{}
+		for _, mode := range p.SupportedModes {
+			modes = append(modes, PinMode(mode))
+		}
+		capabilities[pin] = modes
+	}
+	return capabilities
+}
+
+// supportsMode tells whether any pin of the board supports mode. Boards which
+// did not report their capabilities are assumed to support it.
+func (f *Adaptor) supportsMode(mode PinMode) bool {
+	reported := false
+	for _, p := range f.Board.Pins() {
+		for _, m := range p.SupportedModes {
+			if PinMode(m) == mode {
+				return true
+			}
+		}
+		reported = reported || len(p.SupportedModes) > 0
+	}
+	return !reported
+}
+
+// setPinMode sets the mode of pin, returning an error if the board reported
+// the pin does not support it.
+func (f *Adaptor) setPinMode(pin int, mode PinMode) error {
+	// boards which did not report their capabilities are not validated
+	if supported := f.Board.Pins()[pin].SupportedModes; len(supported) > 0 {
+		ok := false
+		for _, m := range supported {
+			if PinMode(m) == mode {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("pin %d does not support %v", pin, mode)
+		}
+	}
+
+	return f.Board.SetPinMode(pin, int(mode))
+}
@@ -0,0 +1,43 @@
+package firmata
+
+import (
+	"errors"
+	"testing"
+
+	"gobot.io/x/gobot/gobottest"
+	"gobot.io/x/gobot/platforms/firmata/client"
+)
+
+func TestAdaptorCapabilities(t *testing.T) {
+	a := initTestAdaptor()
+	a.Board.Pins()[13].SupportedModes = []int{client.Input, client.Output}
+	a.Board.Pins()[3].SupportedModes = []int{client.Input, client.Output, client.Pwm, client.Servo}
+
+	capabilities := a.Capabilities()
+	gobottest.Assert(t, len(capabilities), len(a.Board.Pins()))
+	gobottest.Assert(t, capabilities[13], []PinMode{InputMode, OutputMode})
+	gobottest.Assert(t, capabilities[3], []PinMode{InputMode, OutputMode, PwmMode, ServoMode})
+	gobottest.Assert(t, capabilities[0], []PinMode{})
+}
+
+func TestAdaptorPinModeValidation(t *testing.T) {
+	a := initTestAdaptor()
+	a.Board.Pins()[13].SupportedModes = []int{client.Input, client.Output}
+	a.Board.Pins()[3].SupportedModes = []int{client.Input, client.Output, client.Pwm, client.Servo}
+
+	gobottest.Assert(t, a.PwmWrite("13", 50), errors.New("pin 13 does not support PWM"))
+	gobottest.Assert(t, a.ServoWrite("13", 50), errors.New("pin 13 does not support servo"))
+	gobottest.Assert(t, a.PwmWrite("3", 50), nil)
+	gobottest.Assert(t, a.ServoWrite("3", 50), nil)
+	_, err := a.PullupRead("13")
+	gobottest.Assert(t, err, errors.New("pin 13 does not support pullup"))
+
+	// pins without reported capabilities are not validated
+	gobottest.Assert(t, a.PwmWrite("4", 50), nil)
+}
+
+func TestPinModeString(t *testing.T) {
+	gobottest.Assert(t, PwmMode.String(), "PWM")
+	gobottest.Assert(t, AnalogMode.String(), "analog")
+	gobottest.Assert(t, PinMode(0x06).String(), "mode 6")
+}
@@ -13,6 +13,9 @@ type TCPAdaptor struct {
 	*Adaptor
 }
 
+// tcpScheme prefixes the port of boards running Firmata over TCP
+const tcpScheme = "tcp://"
+
 func connect(address string) (io.ReadWriteCloser, error) {
 	return net.Dial("tcp", address)
 }
@@ -1,8 +1,13 @@
 package firmata
 
 import (
+	"errors"
+	"io"
+	"net"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"gobot.io/x/gobot"
 	"gobot.io/x/gobot/gobottest"
@@ -19,3 +24,55 @@ func TestFirmataTCPAdaptor(t *testing.T) {
 	a := initTestTCPAdaptor()
 	gobottest.Assert(t, strings.HasPrefix(a.Name(), "TCPFirmata"), true)
 }
+
+func TestFirmataAdaptorTCPPort(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	gobottest.Assert(t, err, nil)
+	defer ln.Close()
+
+	a := NewAdaptor("tcp://" + ln.Addr().String())
+	conn, err := a.PortOpener(a.Port())
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, conn.Close(), nil)
+}
+
+func TestFirmataAdaptorTCPReconnect(t *testing.T) {
+	var mtx sync.Mutex
+	opened := 0
+
+	a := NewAdaptor("tcp://localhost:4567")
+	a.Board = newMockFirmataBoard()
+	a.reconnectInterval = time.Millisecond
+	a.PortOpener = func(port string) (io.ReadWriteCloser, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		opened++
+		// the first reconnection attempt fails
+		if opened == 2 {
+			return nil, errors.New("connection refused")
+		}
+		return &readWriteCloser{}, nil
+	}
+	gobottest.Assert(t, a.Connect(), nil)
+
+	a.Board.Publish("Error", io.EOF)
+	for i := 0; i < 100; i++ {
+		mtx.Lock()
+		done := opened == 3
+		mtx.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mtx.Lock()
+	gobottest.Assert(t, opened, 3)
+	mtx.Unlock()
+}
+
+func TestFirmataAdaptorReconnectSuppliedConnection(t *testing.T) {
+	a := NewAdaptor("tcp://localhost:4567", &readWriteCloser{})
+	a.Board = newMockFirmataBoard()
+	gobottest.Assert(t, a.Connect(), nil)
+	gobottest.Refute(t, a.Reconnect(), nil)
+}
@@ -67,16 +67,32 @@ func (m mockFirmataBoard) Disconnect() error {
 func (m mockFirmataBoard) Pins() []client.Pin {
 	return m.pins
 }
-func (mockFirmataBoard) AnalogWrite(int, int) error      { return nil }
-func (mockFirmataBoard) SetPinMode(int, int) error       { return nil }
-func (mockFirmataBoard) ReportAnalog(int, int) error     { return nil }
-func (mockFirmataBoard) ReportDigital(int, int) error    { return nil }
-func (mockFirmataBoard) DigitalWrite(int, int) error     { return nil }
-func (mockFirmataBoard) I2cRead(int, int) error          { return nil }
-func (mockFirmataBoard) I2cWrite(int, []byte) error      { return nil }
-func (mockFirmataBoard) I2cConfig(int) error             { return nil }
-func (mockFirmataBoard) ServoConfig(int, int, int) error { return nil }
-func (mockFirmataBoard) WriteSysex(data []byte) error    { return nil }
+func (mockFirmataBoard) AnalogWrite(int, int) error                  { return nil }
+func (mockFirmataBoard) ExtendedAnalogWrite(int, int) error          { return nil }
+func (mockFirmataBoard) SetPinMode(int, int) error                   { return nil }
+func (mockFirmataBoard) ReportAnalog(int, int) error                 { return nil }
+func (mockFirmataBoard) ReportDigital(int, int) error                { return nil }
+func (mockFirmataBoard) FirmwareQuery() error                        { return nil }
+func (mockFirmataBoard) AnalogMappingQuery() error                   { return nil }
+func (mockFirmataBoard) PinStateQuery(int) error                     { return nil }
+func (mockFirmataBoard) SetSamplingInterval(int) error               { return nil }
+func (mockFirmataBoard) DigitalWrite(int, int) error                 { return nil }
+func (mockFirmataBoard) DigitalWritePins(map[int]int) error          { return nil }
+func (mockFirmataBoard) I2cRead(int, int) error                      { return nil }
+func (mockFirmataBoard) I2cReadRegister(int, int, int) error         { return nil }
+func (mockFirmataBoard) I2cWrite(int, []byte) error                  { return nil }
+func (mockFirmataBoard) I2cTransfer(int, []byte, int) error          { return nil }
+func (mockFirmataBoard) I2cConfig(int) error                         { return nil }
+func (mockFirmataBoard) ServoConfig(int, int, int) error             { return nil }
+func (mockFirmataBoard) Tone(int, int, int) error                    { return nil }
+func (mockFirmataBoard) NoTone(int) error                            { return nil }
+func (mockFirmataBoard) StepperConfig(int, int, int, int, int) error { return nil }
+func (mockFirmataBoard) StepperStep(int, int, int, int) error        { return nil }
+func (mockFirmataBoard) EncoderAttach(int, int, int) error           { return nil }
+func (mockFirmataBoard) EncoderReset(int) error                      { return nil }
+func (mockFirmataBoard) EncoderDetach(int) error                     { return nil }
+func (mockFirmataBoard) EncoderReportAuto(bool) error                { return nil }
+func (mockFirmataBoard) WriteSysex(data []byte) error                { return nil }
 
 func initTestIMUDriver() *IMUDriver {
 	a := firmata.NewAdaptor("/dev/null")
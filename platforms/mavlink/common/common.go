@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"reflect"
 )
 
 var messages = map[uint8]MAVLinkMessage{
@@ -132,6 +133,11 @@ var messages = map[uint8]MAVLinkMessage{
 func NewMAVLinkMessage(msgid uint8, data []byte) (MAVLinkMessage, error) {
 	message := messages[msgid]
 	if message != nil {
+		if len(data) < int(message.Len()) {
+			return nil, fmt.Errorf("Short payload for Message ID %v: %v of %v bytes", msgid, len(data), message.Len())
+		}
+		// decode into a new message, so earlier ones aren't overwritten
+		message = reflect.New(reflect.TypeOf(message).Elem()).Interface().(MAVLinkMessage)
 		message.Decode(data)
 		return message, nil
 	}
@@ -8,8 +8,8 @@ package mavlink
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"io"
-	"time"
 )
 
 const (
@@ -24,6 +24,9 @@ const (
 	X25_VALIDATE_CRC       = 0xf0b8
 )
 
+// ErrInvalidChecksum is returned when a packet's checksum doesn't match its contents
+var ErrInvalidChecksum = errors.New("Invalid checksum")
+
 var sequence uint16 = 0
 
 func generateSequence() uint8 {
@@ -53,7 +56,9 @@ type MAVLinkPacket struct {
 }
 
 // ReadMAVLinkPacket reads an io.Reader for a new packet and returns a new MAVLink packet
-// or returns the error received by the io.Reader
+// or returns the error received by the io.Reader. ErrInvalidChecksum is returned
+// for packets of known messages whose checksum, including the message CRC_EXTRA,
+// doesn't match.
 func ReadMAVLinkPacket(r io.Reader) (*MAVLinkPacket, error) {
 	for {
 		header, err := read(r, 1)
@@ -68,12 +73,16 @@ func ReadMAVLinkPacket(r io.Reader) (*MAVLinkPacket, error) {
 				continue
 			}
 			m := &MAVLinkPacket{}
-			data, err := read(r, int(length[0])+7)
+			// sequence, system, component and message id, payload and checksum
+			data, err := read(r, int(length[0])+6)
 			if err != nil {
 				return nil, err
 			}
 			data = append([]byte{header[0], length[0]}, data...)
 			m.Decode(data)
+			if !m.validChecksum() {
+				return nil, ErrInvalidChecksum
+			}
 			return m, nil
 		}
 	}
@@ -136,30 +145,27 @@ func (m *MAVLinkPacket) Decode(buf []byte) {
 	m.ComponentID = buf[4]
 	m.MessageID = buf[5]
 	m.Data = buf[6 : 6+int(m.Length)]
-	checksum := buf[7+int(m.Length):]
+	checksum := buf[6+int(m.Length):]
 	m.Checksum = uint16(checksum[1])<<8 | uint16(checksum[0])
 }
 
 func read(r io.Reader, length int) ([]byte, error) {
-	buf := []byte{}
-	for length > 0 {
-		tmp := make([]byte, length)
-		i, err := r.Read(tmp[:])
-		if err != nil {
-			return nil, err
-		} else {
-			length -= i
-			buf = append(buf, tmp...)
-			if length != i {
-				time.Sleep(1 * time.Millisecond)
-			} else {
-				break
-			}
-		}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
 	}
 	return buf, nil
 }
 
+// validChecksum returns false if the checksum of a packet for a known message
+// doesn't match its contents
+func (m *MAVLinkPacket) validChecksum() bool {
+	if messages[m.MessageID] == nil {
+		return true
+	}
+	return crcCalculate(m) == m.Checksum
+}
+
 //
 // Accumulate the X.25 CRC by adding one char at a time.
 //
@@ -197,7 +203,8 @@ func crcCalculate(m *MAVLinkPacket) uint16 {
 	for _, v := range m.Pack()[1 : m.Length+6] {
 		crc = crcAccumulate(v, crc)
 	}
-	message, _ := m.MAVLinkMessage()
-	crc = crcAccumulate(message.Crc(), crc)
+	if message := messages[m.MessageID]; message != nil {
+		crc = crcAccumulate(message.Crc(), crc)
+	}
 	return crc
 }
@@ -0,0 +1,72 @@
+package mavlink
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"gobot.io/x/gobot/gobottest"
+)
+
+// a HEARTBEAT from an ArduCopter, system 1 component 1
+var heartbeatFrame = []byte{
+	0xfe, 0x09, 0x4e, 0x01, 0x01, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x51, 0x04, 0x03,
+	0x1c, 0x7f,
+}
+
+func TestReadMAVLinkPacketHeartbeat(t *testing.T) {
+	// leading noise is skipped
+	r := bytes.NewReader(append([]byte{0x00, 0x42}, heartbeatFrame...))
+	p, err := ReadMAVLinkPacket(r)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, p.Length, uint8(9))
+	gobottest.Assert(t, p.Sequence, uint8(0x4e))
+	gobottest.Assert(t, p.SystemID, uint8(1))
+	gobottest.Assert(t, p.ComponentID, uint8(1))
+	gobottest.Assert(t, p.MessageID, uint8(0))
+	gobottest.Assert(t, p.Checksum, uint16(0x7f1c))
+	gobottest.Assert(t, p.Pack(), heartbeatFrame)
+
+	m, err := p.MAVLinkMessage()
+	gobottest.Assert(t, err, nil)
+	h := m.(*Heartbeat)
+	gobottest.Assert(t, h.TYPE, uint8(MAV_TYPE_QUADROTOR))
+	gobottest.Assert(t, h.AUTOPILOT, uint8(MAV_AUTOPILOT_ARDUPILOTMEGA))
+	gobottest.Assert(t, h.BASE_MODE, uint8(0x51))
+	gobottest.Assert(t, h.SYSTEM_STATUS, uint8(MAV_STATE_ACTIVE))
+	gobottest.Assert(t, h.MAVLINK_VERSION, uint8(3))
+
+	_, err = ReadMAVLinkPacket(r)
+	gobottest.Assert(t, err, io.EOF)
+}
+
+func TestReadMAVLinkPacketInvalidChecksum(t *testing.T) {
+	frame := append([]byte{}, heartbeatFrame...)
+	frame[10] = 0x01
+	_, err := ReadMAVLinkPacket(bytes.NewReader(frame))
+	gobottest.Assert(t, err, ErrInvalidChecksum)
+}
+
+func TestReadMAVLinkPacketShortFrame(t *testing.T) {
+	_, err := ReadMAVLinkPacket(bytes.NewReader(heartbeatFrame[:10]))
+	gobottest.Assert(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestNewMAVLinkPacketChecksum(t *testing.T) {
+	p := NewMAVLinkPacket(MAVLINK_10_STX, 9, 0x4e, 1, 1, 0, heartbeatFrame[6:15])
+	gobottest.Assert(t, p.Checksum, uint16(0x7f1c))
+
+	// unknown messages have no CRC_EXTRA to add
+	p = NewMAVLinkPacket(MAVLINK_10_STX, 0, 0, 1, 1, 200, []byte{})
+	gobottest.Assert(t, p.validChecksum(), true)
+}
+
+func TestNewMAVLinkMessageShortPayload(t *testing.T) {
+	_, err := NewMAVLinkMessage(33, make([]byte, 27))
+	gobottest.Assert(t, err.Error(), "Short payload for Message ID 33: 27 of 28 bytes")
+
+	m, err := NewMAVLinkMessage(33, make([]byte, 28))
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, m.Id(), uint8(33))
+}
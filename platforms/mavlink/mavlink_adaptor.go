@@ -2,6 +2,7 @@ package mavlink
 
 import (
 	"io"
+	"sync"
 
 	serial "go.bug.st/serial.v1"
 	"gobot.io/x/gobot"
@@ -20,25 +21,50 @@ type BaseAdaptor interface {
 type Adaptor struct {
 	name    string
 	port    string
+	baud    int
 	sp      io.ReadWriteCloser
 	connect func(string) (io.ReadWriteCloser, error)
+	// SystemID and ComponentID identify this adaptor in the packets it
+	// crafts. They default to a mission planner ground station, 255 and 190.
+	SystemID    uint8
+	ComponentID uint8
+	sequence    uint8
+	mutex       sync.Mutex
 }
 
-// NewAdaptor creates a new mavlink adaptor with specified port
-func NewAdaptor(port string) *Adaptor {
-	return &Adaptor{
-		name: "Mavlink",
-		port: port,
-		connect: func(port string) (io.ReadWriteCloser, error) {
-			return serial.Open(port, &serial.Mode{BaudRate: 57600})
-		},
+// NewAdaptor creates a new mavlink adaptor with specified port, which
+// optionally accepts:
+//
+//	int: baud rate of the serial port, defaults to 57600 as used by telemetry
+//	radios, while USB links to the flight controller usually need 115200
+func NewAdaptor(port string, args ...interface{}) *Adaptor {
+	m := &Adaptor{
+		name:        "Mavlink",
+		port:        port,
+		baud:        57600,
+		SystemID:    255,
+		ComponentID: common.MAV_COMP_ID_MISSIONPLANNER,
 	}
+	m.connect = func(port string) (io.ReadWriteCloser, error) {
+		return serial.Open(port, &serial.Mode{BaudRate: m.baud})
+	}
+
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case int:
+			m.baud = arg
+		}
+	}
+	return m
 }
 
 func (m *Adaptor) Name() string     { return m.name }
 func (m *Adaptor) SetName(n string) { m.name = n }
 func (m *Adaptor) Port() string     { return m.port }
 
+// Baud returns the baud rate of the serial port
+func (m *Adaptor) Baud() int { return m.baud }
+
 // Connect returns true if connection to device is successful
 func (m *Adaptor) Connect() (err error) {
 	if sp, e := m.connect(m.Port()); e != nil {
@@ -62,3 +88,33 @@ func (m *Adaptor) ReadMAVLinkPacket() (*common.MAVLinkPacket, error) {
 func (m *Adaptor) Write(b []byte) (int, error) {
 	return m.sp.Write(b)
 }
+
+// SendPacket writes packet to the device, after setting its sequence number
+// to the next one of this adaptor and recomputing its checksum
+func (m *Adaptor) SendPacket(packet *common.MAVLinkPacket) (err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	crafted := common.NewMAVLinkPacket(packet.Protocol, packet.Length, m.sequence,
+		packet.SystemID, packet.ComponentID, packet.MessageID, packet.Data)
+	packet.Sequence = crafted.Sequence
+	packet.Checksum = crafted.Checksum
+	if _, err = m.Write(packet.Pack()); err != nil {
+		return
+	}
+	m.sequence++
+	return
+}
+
+// SendRequestDataStream asks the targetSystem's targetComponent to start, or
+// stop, sending the streamID data stream (one of the common.MAV_DATA_STREAM
+// constants) at rate Hz
+func (m *Adaptor) SendRequestDataStream(targetSystem, targetComponent, streamID uint8, rate uint16, start bool) error {
+	var startStop uint8
+	if start {
+		startStop = 1
+	}
+	message := common.NewRequestDataStream(rate, targetSystem, targetComponent, streamID, startStop)
+	return m.SendPacket(common.NewMAVLinkPacket(common.MAVLINK_10_STX, message.Len(), 0,
+		m.SystemID, m.ComponentID, message.Id(), message.Pack()))
+}
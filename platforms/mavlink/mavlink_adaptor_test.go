@@ -8,13 +8,14 @@ import (
 
 	"gobot.io/x/gobot"
 	"gobot.io/x/gobot/gobottest"
+	common "gobot.io/x/gobot/platforms/mavlink/common"
 )
 
 var _ gobot.Adaptor = (*Adaptor)(nil)
 
 type nullReadWriteCloser struct{}
 
-var payload = []byte{0xFE, 0x09, 0x4E, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x51, 0x04, 0x03, 0x1C, 0x7F}
+var payload = []byte{0xFE, 0x09, 0x4E, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x51, 0x04, 0x03, 0x1C, 0x7F}
 
 var testAdaptorRead = func(p []byte) (int, error) {
 	return len(p), nil
@@ -57,6 +58,15 @@ func TestMavlinkAdaptor(t *testing.T) {
 	gobottest.Assert(t, a.Port(), "/dev/null")
 }
 
+func TestMavlinkAdaptorBaud(t *testing.T) {
+	a := initTestMavlinkAdaptor()
+	gobottest.Assert(t, a.Baud(), 57600)
+
+	a = NewAdaptor("/dev/ttyACM0", 115200)
+	gobottest.Assert(t, a.Baud(), 115200)
+	gobottest.Assert(t, a.Port(), "/dev/ttyACM0")
+}
+
 func TestMavlinkAdaptorName(t *testing.T) {
 	a := initTestMavlinkAdaptor()
 	gobottest.Assert(t, strings.HasPrefix(a.Name(), "Mavlink"), true)
@@ -81,3 +91,48 @@ func TestMavlinkAdaptorFinalize(t *testing.T) {
 	}
 	gobottest.Assert(t, a.Finalize(), errors.New("close error"))
 }
+
+type recordingWriteCloser struct {
+	nullReadWriteCloser
+	written []byte
+}
+
+func (r *recordingWriteCloser) Write(b []byte) (int, error) {
+	r.written = append(r.written, b...)
+	return len(b), nil
+}
+
+func TestMavlinkAdaptorSendRequestDataStream(t *testing.T) {
+	a := initTestMavlinkAdaptor()
+	w := &recordingWriteCloser{}
+	a.sp = w
+
+	gobottest.Assert(t, a.SendRequestDataStream(1, 1, common.MAV_DATA_STREAM_EXTRA1, 4, true), nil)
+	gobottest.Assert(t, w.written, []byte{
+		0xfe, 0x06, 0x00, 0xff, 0xbe, 0x42,
+		0x04, 0x00, 0x01, 0x01, 0x0a, 0x01,
+		0xed, 0x01,
+	})
+
+	// the sequence number is incremented for every packet
+	w.written = []byte{}
+	gobottest.Assert(t, a.SendRequestDataStream(1, 1, common.MAV_DATA_STREAM_EXTRA1, 4, true), nil)
+	gobottest.Assert(t, w.written[2], uint8(1))
+	gobottest.Assert(t, w.written[12:], []byte{0x7c, 0x54})
+}
+
+func TestMavlinkAdaptorSendPacketError(t *testing.T) {
+	a := initTestMavlinkAdaptor()
+	testAdaptorRead = func(p []byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	defer func() {
+		testAdaptorRead = func(p []byte) (int, error) {
+			return len(p), nil
+		}
+	}()
+
+	p := common.NewMAVLinkPacket(common.MAVLINK_10_STX, 0, 0, 255, 190, 0, []byte{})
+	gobottest.Assert(t, a.SendPacket(p), errors.New("write error"))
+	gobottest.Assert(t, a.sequence, uint8(0))
+}
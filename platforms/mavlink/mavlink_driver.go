@@ -1,6 +1,9 @@
 package mavlink
 
 import (
+	"bytes"
+	"fmt"
+	"sync"
 	"time"
 
 	"gobot.io/x/gobot"
@@ -16,12 +19,97 @@ const (
 	ErrorIOEvent = "errorIO"
 	// ErrorMAVLinkEvent event
 	ErrorMAVLinkEvent = "errorMAVLink"
+	// HeartbeatEvent event
+	HeartbeatEvent = "heartbeat"
+	// DisconnectedEvent event
+	DisconnectedEvent = "disconnected"
+	// GPSEvent event
+	GPSEvent = "gps"
+	// AttitudeEvent event
+	AttitudeEvent = "attitude"
+	// ParamEvent event
+	ParamEvent = "param"
 )
 
+// GlobalPosition is the position reported by a GLOBAL_POSITION_INT message
+type GlobalPosition struct {
+	// Lat and Lon are in degrees * 1E7
+	Lat int32
+	Lon int32
+	// Alt, above MSL, and RelativeAlt, above ground, are in millimeters
+	Alt         int32
+	RelativeAlt int32
+	// Vx, Vy and Vz are the ground speeds in m/s * 100
+	Vx int16
+	Vy int16
+	Vz int16
+	// Hdg is the heading in degrees * 100, or 65535 if unknown
+	Hdg uint16
+}
+
+// Latitude returns the latitude in degrees
+func (g GlobalPosition) Latitude() float64 { return float64(g.Lat) / 1e7 }
+
+// Longitude returns the longitude in degrees
+func (g GlobalPosition) Longitude() float64 { return float64(g.Lon) / 1e7 }
+
+// Altitude returns the altitude above MSL in meters
+func (g GlobalPosition) Altitude() float64 { return float64(g.Alt) / 1000 }
+
+// RelativeAltitude returns the altitude above ground in meters
+func (g GlobalPosition) RelativeAltitude() float64 { return float64(g.RelativeAlt) / 1000 }
+
+// Heading returns the heading in degrees, or false if it is unknown
+func (g GlobalPosition) Heading() (float64, bool) {
+	if g.Hdg == 65535 {
+		return 0, false
+	}
+	return float64(g.Hdg) / 100, true
+}
+
+// Attitude is the orientation reported by an ATTITUDE message
+type Attitude struct {
+	// TimeBootMs is the time since the vehicle booted in milliseconds
+	TimeBootMs uint32
+	// Roll, Pitch and Yaw are in radians, from -pi to +pi
+	Roll  float32
+	Pitch float32
+	Yaw   float32
+	// RollSpeed, PitchSpeed and YawSpeed are in radians per second
+	RollSpeed  float32
+	PitchSpeed float32
+	YawSpeed   float32
+}
+
+// Param is an onboard parameter reported by a PARAM_VALUE message
+type Param struct {
+	ID    string
+	Value float32
+	// Type is one of the common.MAV_PARAM_TYPE constants
+	Type byte
+	// Count is the number of onboard parameters and Index the index of
+	// this one
+	Count uint16
+	Index uint16
+}
+
 type Driver struct {
 	name       string
 	connection gobot.Connection
 	interval   time.Duration
+	// HeartbeatInterval is how often the vehicle is expected to send a
+	// HEARTBEAT, 1 second by default.
+	HeartbeatInterval time.Duration
+	// MaxMissedHeartbeats is how many heartbeats can be missed in a row
+	// before the vehicle is considered disconnected, 3 by default.
+	MaxMissedHeartbeats int
+	// TargetSystem and TargetComponent identify the vehicle addressed by
+	// RequestParam and SetParam, 1 and 1 by default.
+	TargetSystem    uint8
+	TargetComponent uint8
+	mutex           sync.Mutex
+	lastHeartbeat   time.Time
+	halt            chan bool
 	gobot.Eventer
 }
 
@@ -31,14 +119,24 @@ type MavlinkInterface interface {
 // NewDriver creates a new mavlink driver.
 //
 // It add the following events:
+//
 //	"packet" - triggered when a new packet is read
 //	"message" - triggered when a new valid message is processed
+//	"heartbeat" - triggered with the *common.Heartbeat of every HEARTBEAT message
+//	"disconnected" - triggered once MaxMissedHeartbeats heartbeats in a row are missed
+//	"gps" - triggered with the GlobalPosition of every GLOBAL_POSITION_INT message
+//	"attitude" - triggered with the Attitude of every ATTITUDE message
+//	"param" - triggered with the Param of every PARAM_VALUE message
 func NewDriver(a BaseAdaptor, v ...time.Duration) *Driver {
 	m := &Driver{
-		name:       "Mavlink",
-		connection: a,
-		Eventer:    gobot.NewEventer(),
-		interval:   10 * time.Millisecond,
+		name:                "Mavlink",
+		connection:          a,
+		Eventer:             gobot.NewEventer(),
+		interval:            10 * time.Millisecond,
+		HeartbeatInterval:   1 * time.Second,
+		MaxMissedHeartbeats: 3,
+		TargetSystem:        1,
+		TargetComponent:     1,
 	}
 
 	if len(v) > 0 {
@@ -49,6 +147,11 @@ func NewDriver(a BaseAdaptor, v ...time.Duration) *Driver {
 	m.AddEvent(MessageEvent)
 	m.AddEvent(ErrorIOEvent)
 	m.AddEvent(ErrorMAVLinkEvent)
+	m.AddEvent(HeartbeatEvent)
+	m.AddEvent(DisconnectedEvent)
+	m.AddEvent(GPSEvent)
+	m.AddEvent(AttitudeEvent)
+	m.AddEvent(ParamEvent)
 
 	return m
 }
@@ -68,6 +171,10 @@ func (m *Driver) Start() error {
 	go func() {
 		for {
 			packet, err := m.adaptor().ReadMAVLinkPacket()
+			if err == common.ErrInvalidChecksum {
+				m.Publish(ErrorMAVLinkEvent, err)
+				continue
+			}
 			if err != nil {
 				m.Publish(ErrorIOEvent, err)
 				continue
@@ -79,17 +186,141 @@ func (m *Driver) Start() error {
 				continue
 			}
 			m.Publish(MessageEvent, message)
+			switch message := message.(type) {
+			case *common.Heartbeat:
+				m.mutex.Lock()
+				m.lastHeartbeat = time.Now()
+				m.mutex.Unlock()
+				m.Publish(HeartbeatEvent, message)
+			case *common.GlobalPositionInt:
+				m.Publish(GPSEvent, GlobalPosition{
+					Lat:         message.LAT,
+					Lon:         message.LON,
+					Alt:         message.ALT,
+					RelativeAlt: message.RELATIVE_ALT,
+					Vx:          message.VX,
+					Vy:          message.VY,
+					Vz:          message.VZ,
+					Hdg:         message.HDG,
+				})
+			case *common.Attitude:
+				m.Publish(AttitudeEvent, Attitude{
+					TimeBootMs: message.TIME_BOOT_MS,
+					Roll:       message.ROLL,
+					Pitch:      message.PITCH,
+					Yaw:        message.YAW,
+					RollSpeed:  message.ROLLSPEED,
+					PitchSpeed: message.PITCHSPEED,
+					YawSpeed:   message.YAWSPEED,
+				})
+			case *common.ParamValue:
+				m.Publish(ParamEvent, Param{
+					ID:    decodeParamID(message.PARAM_ID),
+					Value: message.PARAM_VALUE,
+					Type:  message.PARAM_TYPE,
+					Count: message.PARAM_COUNT,
+					Index: message.PARAM_INDEX,
+				})
+			}
 			time.Sleep(m.interval)
 		}
 	}()
+	m.mutex.Lock()
+	m.halt = make(chan bool)
+	go m.watchHeartbeats(m.halt)
+	m.mutex.Unlock()
 	return nil
 }
 
+// watchHeartbeats publishes a DisconnectedEvent when no heartbeat has been
+// received for MaxMissedHeartbeats heartbeat intervals. Only vehicles which
+// have sent a heartbeat can be disconnected.
+func (m *Driver) watchHeartbeats(halt chan bool) {
+	ticker := time.NewTicker(m.HeartbeatInterval)
+	defer ticker.Stop()
+	timeout := time.Duration(m.MaxMissedHeartbeats) * m.HeartbeatInterval
+	for {
+		select {
+		case <-ticker.C:
+			m.mutex.Lock()
+			disconnected := !m.lastHeartbeat.IsZero() && time.Since(m.lastHeartbeat) > timeout
+			if disconnected {
+				m.lastHeartbeat = time.Time{}
+			}
+			m.mutex.Unlock()
+			if disconnected {
+				m.Publish(DisconnectedEvent, nil)
+			}
+		case <-halt:
+			return
+		}
+	}
+}
+
 // Halt returns true if device is halted successfully
-func (m *Driver) Halt() (err error) { return }
+func (m *Driver) Halt() (err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.halt != nil {
+		close(m.halt)
+		m.halt = nil
+	}
+	return
+}
 
 // SendPacket sends a packet to mavlink device
 func (m *Driver) SendPacket(packet *common.MAVLinkPacket) (err error) {
 	_, err = m.adaptor().Write(packet.Pack())
 	return err
 }
+
+// RequestParam asks the vehicle for the value of the id parameter, which is
+// published as a ParamEvent once received.
+func (m *Driver) RequestParam(id string) error {
+	paramID, err := encodeParamID(id)
+	if err != nil {
+		return err
+	}
+	return m.sendMessage(common.NewParamRequestRead(-1, m.TargetSystem, m.TargetComponent, paramID))
+}
+
+// SetParam sets the id parameter of the vehicle to value, of paramType, one
+// of the common.MAV_PARAM_TYPE constants. The vehicle acknowledges it with a
+// ParamEvent carrying the value it stored.
+func (m *Driver) SetParam(id string, value float32, paramType byte) error {
+	paramID, err := encodeParamID(id)
+	if err != nil {
+		return err
+	}
+	return m.sendMessage(common.NewParamSet(value, m.TargetSystem, m.TargetComponent, paramID, paramType))
+}
+
+// sendMessage sends message to the vehicle, through the serial adaptor
+// SendPacket so that it gets the sequence number and ids of the adaptor.
+func (m *Driver) sendMessage(message common.MAVLinkMessage) error {
+	packet := common.CraftMAVLinkPacket(255, common.MAV_COMP_ID_MISSIONPLANNER, message)
+	if a, ok := m.adaptor().(*Adaptor); ok {
+		packet.SystemID, packet.ComponentID = a.SystemID, a.ComponentID
+		return a.SendPacket(packet)
+	}
+	return m.SendPacket(packet)
+}
+
+// encodeParamID null pads id to the 16 bytes of a parameter id. An id of
+// exactly 16 characters has no null termination.
+func encodeParamID(id string) (paramID [16]uint8, err error) {
+	if len(id) > len(paramID) {
+		return paramID, fmt.Errorf("Invalid param id %q, must be at most %d characters", id, len(paramID))
+	}
+	copy(paramID[:], id)
+	return
+}
+
+// decodeParamID returns the id of a parameter up to its null termination,
+// if any.
+func decodeParamID(paramID [16]uint8) string {
+	if i := bytes.IndexByte(paramID[:], 0); i >= 0 {
+		return string(paramID[:i])
+	}
+	return string(paramID[:])
+}
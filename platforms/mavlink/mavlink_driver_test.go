@@ -3,6 +3,7 @@ package mavlink
 import (
 	"io"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -84,3 +85,204 @@ func TestMavlinkDriverHalt(t *testing.T) {
 	d := initTestMavlinkDriver()
 	gobottest.Assert(t, d.Halt(), nil)
 }
+
+type heartbeatReader struct {
+	mtx    sync.Mutex
+	frames []byte
+}
+
+func (h *heartbeatReader) Read(b []byte) (int, error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if len(h.frames) == 0 {
+		// the vehicle went silent
+		time.Sleep(10 * time.Millisecond)
+		return 0, io.EOF
+	}
+	n := copy(b, h.frames)
+	h.frames = h.frames[n:]
+	return n, nil
+}
+
+func (h *heartbeatReader) Write(b []byte) (int, error) { return len(b), nil }
+func (h *heartbeatReader) Close() error                { return nil }
+
+func TestMavlinkDriverHeartbeat(t *testing.T) {
+	a := NewAdaptor("/dev/null")
+	a.sp = &heartbeatReader{frames: []byte{
+		0xfe, 0x09, 0x4e, 0x01, 0x01, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x51, 0x04, 0x03,
+		0x1c, 0x7f,
+	}}
+	d := NewDriver(a)
+	d.HeartbeatInterval = 10 * time.Millisecond
+
+	heartbeat := make(chan *common.Heartbeat, 1)
+	disconnected := make(chan bool, 1)
+	d.On(HeartbeatEvent, func(data interface{}) {
+		heartbeat <- data.(*common.Heartbeat)
+	})
+	d.On(DisconnectedEvent, func(data interface{}) {
+		disconnected <- true
+	})
+
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	select {
+	case h := <-heartbeat:
+		gobottest.Assert(t, h.TYPE, uint8(common.MAV_TYPE_QUADROTOR))
+		gobottest.Assert(t, h.AUTOPILOT, uint8(common.MAV_AUTOPILOT_ARDUPILOTMEGA))
+		gobottest.Assert(t, h.BASE_MODE, uint8(0x51))
+		gobottest.Assert(t, h.SYSTEM_STATUS, uint8(common.MAV_STATE_ACTIVE))
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("heartbeat was not emitted")
+	}
+
+	select {
+	case <-disconnected:
+	case <-time.After(200 * time.Millisecond):
+		t.Errorf("disconnected was not emitted")
+	}
+
+	// only once per silence
+	select {
+	case <-disconnected:
+		t.Errorf("disconnected was emitted twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestMavlinkDriverGPS(t *testing.T) {
+	position := common.NewGlobalPositionInt(1000, 473977418, 85455939, 488150, 12500, 150, -20, 5, 9000)
+	a := NewAdaptor("/dev/null")
+	a.sp = &heartbeatReader{frames: common.CraftMAVLinkPacket(1, 1, position).Pack()}
+	d := NewDriver(a)
+
+	gps := make(chan GlobalPosition, 1)
+	d.On(GPSEvent, func(data interface{}) {
+		gps <- data.(GlobalPosition)
+	})
+
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	select {
+	case g := <-gps:
+		gobottest.Assert(t, g, GlobalPosition{
+			Lat: 473977418, Lon: 85455939, Alt: 488150, RelativeAlt: 12500,
+			Vx: 150, Vy: -20, Vz: 5, Hdg: 9000,
+		})
+		gobottest.Assert(t, g.Latitude(), 47.3977418)
+		gobottest.Assert(t, g.Longitude(), 8.5455939)
+		gobottest.Assert(t, g.Altitude(), 488.15)
+		gobottest.Assert(t, g.RelativeAltitude(), 12.5)
+		heading, ok := g.Heading()
+		gobottest.Assert(t, heading, 90.0)
+		gobottest.Assert(t, ok, true)
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("gps was not emitted")
+	}
+
+	_, ok := GlobalPosition{Hdg: 65535}.Heading()
+	gobottest.Assert(t, ok, false)
+}
+
+func TestMavlinkDriverAttitude(t *testing.T) {
+	packet := common.CraftMAVLinkPacket(1, 1, common.NewAttitude(2500, 1, -0.5, 3.1415927, 0.25, -2, 0))
+	// the floats are little endian IEEE-754 whatever the host byte order
+	gobottest.Assert(t, packet.Data[4:8], []byte{0x00, 0x00, 0x80, 0x3f})
+	gobottest.Assert(t, packet.Data[8:12], []byte{0x00, 0x00, 0x00, 0xbf})
+
+	a := NewAdaptor("/dev/null")
+	a.sp = &heartbeatReader{frames: packet.Pack()}
+	d := NewDriver(a)
+
+	attitude := make(chan Attitude, 1)
+	d.On(AttitudeEvent, func(data interface{}) {
+		attitude <- data.(Attitude)
+	})
+
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	select {
+	case a := <-attitude:
+		gobottest.Assert(t, a, Attitude{
+			TimeBootMs: 2500, Roll: 1, Pitch: -0.5, Yaw: 3.1415927,
+			RollSpeed: 0.25, PitchSpeed: -2, YawSpeed: 0,
+		})
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("attitude was not emitted")
+	}
+
+	_, err := common.NewMAVLinkMessage(30, packet.Data[:27])
+	gobottest.Assert(t, err.Error(), "Short payload for Message ID 30: 27 of 28 bytes")
+}
+
+func TestMavlinkDriverRequestParam(t *testing.T) {
+	a := NewAdaptor("/dev/null")
+	w := &recordingWriteCloser{}
+	a.sp = w
+	d := NewDriver(a)
+
+	gobottest.Assert(t, d.RequestParam("RATE_RLL_P"), nil)
+	gobottest.Assert(t, w.written[:6], []byte{0xfe, 0x14, 0x00, 0xff, 0xbe, 0x14})
+	gobottest.Assert(t, w.written[6:26], []byte{
+		0xff, 0xff, 0x01, 0x01,
+		'R', 'A', 'T', 'E', '_', 'R', 'L', 'L', '_', 'P', 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	})
+
+	err := d.RequestParam("COMPASS_OFS_X_ABC")
+	gobottest.Assert(t, err.Error(), "Invalid param id \"COMPASS_OFS_X_ABC\", must be at most 16 characters")
+}
+
+func TestMavlinkDriverSetParam(t *testing.T) {
+	a := NewAdaptor("/dev/null")
+	w := &recordingWriteCloser{}
+	a.sp = w
+	d := NewDriver(a)
+	d.TargetSystem = 2
+
+	// ids of exactly 16 characters are not null terminated
+	gobottest.Assert(t, d.SetParam("FS_BATT_VOLTAGE_", 10.5, common.MAV_PARAM_TYPE_REAL32), nil)
+	gobottest.Assert(t, w.written[:6], []byte{0xfe, 0x17, 0x00, 0xff, 0xbe, 0x17})
+	gobottest.Assert(t, w.written[6:29], []byte{
+		0x00, 0x00, 0x28, 0x41, 0x02, 0x01,
+		'F', 'S', '_', 'B', 'A', 'T', 'T', '_', 'V', 'O', 'L', 'T', 'A', 'G', 'E', '_',
+		0x09,
+	})
+}
+
+func TestMavlinkDriverParam(t *testing.T) {
+	var short, full [16]uint8
+	copy(short[:], "SYSID_THISMAV")
+	copy(full[:], "FS_BATT_VOLTAGE_")
+	frames := common.CraftMAVLinkPacket(1, 1, common.NewParamValue(1, 300, 12, short, common.MAV_PARAM_TYPE_UINT8)).Pack()
+	frames = append(frames, common.CraftMAVLinkPacket(1, 1, common.NewParamValue(10.5, 300, 13, full, common.MAV_PARAM_TYPE_REAL32)).Pack()...)
+
+	a := NewAdaptor("/dev/null")
+	a.sp = &heartbeatReader{frames: frames}
+	d := NewDriver(a)
+	d.interval = time.Millisecond
+
+	params := make(chan Param, 2)
+	d.On(ParamEvent, func(data interface{}) {
+		params <- data.(Param)
+	})
+
+	gobottest.Assert(t, d.Start(), nil)
+	defer d.Halt()
+
+	for _, expected := range []Param{
+		{ID: "SYSID_THISMAV", Value: 1, Type: common.MAV_PARAM_TYPE_UINT8, Count: 300, Index: 12},
+		{ID: "FS_BATT_VOLTAGE_", Value: 10.5, Type: common.MAV_PARAM_TYPE_REAL32, Count: 300, Index: 13},
+	} {
+		select {
+		case p := <-params:
+			gobottest.Assert(t, p, expected)
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("param was not emitted")
+		}
+	}
+}
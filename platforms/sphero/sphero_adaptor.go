@@ -2,6 +2,8 @@ package sphero
 
 import (
 	"io"
+	"sync"
+	"time"
 
 	"gobot.io/x/gobot"
 
@@ -12,20 +14,55 @@ import (
 type Adaptor struct {
 	name      string
 	port      string
+	baud      int
+	mtx       sync.Mutex
 	sp        io.ReadWriteCloser
 	connected bool
 	connect   func(string) (io.ReadWriteCloser, error)
+
+	// KeepConnected makes the SpheroDriver reconnect to the Sphero when
+	// reading from or writing to it fails, and configure it again. It is
+	// disabled by default.
+	KeepConnected bool
+
+	// ReconnectInterval is how long the SpheroDriver waits before the first
+	// reconnect attempt, 500ms by default. The wait doubles after each failed
+	// attempt, up to MaxReconnectInterval, 30 seconds by default.
+	ReconnectInterval    time.Duration
+	MaxReconnectInterval time.Duration
 }
 
-// NewAdaptor returns a new Sphero Adaptor given a port
-func NewAdaptor(port string) *Adaptor {
-	return &Adaptor{
+// NewAdaptor returns a new Sphero Adaptor given a port, which optionally accepts:
+//
+//	int: baud rate the Adaptor uses to connect to the serial port, defaults to 115200
+//	io.ReadWriteCloser: connection the Adaptor uses to communicate with the Sphero
+//
+// If an io.ReadWriteCloser is supplied, the Adaptor uses it instead of opening
+// the serial port, and the port is only used as a label.
+func NewAdaptor(port string, args ...interface{}) *Adaptor {
+	a := &Adaptor{
 		name: gobot.DefaultName("Sphero"),
 		port: port,
-		connect: func(port string) (io.ReadWriteCloser, error) {
-			return serial.Open(port, &serial.Mode{BaudRate: 115200})
-		},
+		baud: 115200,
+
+		ReconnectInterval:    500 * time.Millisecond,
+		MaxReconnectInterval: 30 * time.Second,
+	}
+	a.connect = func(port string) (io.ReadWriteCloser, error) {
+		return serial.Open(port, &serial.Mode{BaudRate: a.baud})
+	}
+
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case int:
+			a.baud = arg
+		case io.ReadWriteCloser:
+			a.connect = func(string) (io.ReadWriteCloser, error) {
+				return arg, nil
+			}
+		}
 	}
+	return a
 }
 
 // Name returns the Adaptor's name
@@ -37,11 +74,20 @@ func (a *Adaptor) SetName(n string) { a.name = n }
 // Port returns the Adaptor's port
 func (a *Adaptor) Port() string { return a.port }
 
+// Baud returns the Adaptor's serial baud rate
+func (a *Adaptor) Baud() int { return a.baud }
+
 // SetPort sets the Adaptor's port
 func (a *Adaptor) SetPort(p string) { a.port = p }
 
 // Connect initiates a connection to the Sphero. Returns true on successful connection.
 func (a *Adaptor) Connect() (err error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return a.open()
+}
+
+func (a *Adaptor) open() (err error) {
 	sp, e := a.connect(a.Port())
 	if e != nil {
 		return e
@@ -56,14 +102,22 @@ func (a *Adaptor) Connect() (err error) {
 // it will first close that connection and then establish a new connection.
 // Returns true on Successful reconnection
 func (a *Adaptor) Reconnect() (err error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
 	if a.connected {
-		a.Disconnect()
+		a.close()
 	}
-	return a.Connect()
+	return a.open()
 }
 
 // Disconnect terminates the connection to the Sphero. Returns true on successful disconnect.
 func (a *Adaptor) Disconnect() error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return a.close()
+}
+
+func (a *Adaptor) close() error {
 	if a.connected {
 		if e := a.sp.Close(); e != nil {
 			return e
@@ -73,6 +127,14 @@ func (a *Adaptor) Disconnect() error {
 	return nil
 }
 
+// connection returns the connection to the Sphero, which changes when
+// reconnecting, and whether it is connected.
+func (a *Adaptor) connection() (io.ReadWriteCloser, bool) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return a.sp, a.connected
+}
+
 // Finalize finalizes the Sphero Adaptor
 func (a *Adaptor) Finalize() error {
 	return a.Disconnect()
@@ -45,13 +45,8 @@ func NewNullReadWriteCloser() *nullReadWriteCloser {
 }
 
 func initTestSpheroAdaptor() (*Adaptor, *nullReadWriteCloser) {
-	a := NewAdaptor("/dev/null")
 	rwc := NewNullReadWriteCloser()
-
-	a.connect = func(string) (io.ReadWriteCloser, error) {
-		return rwc, nil
-	}
-	return a, rwc
+	return NewAdaptor("/dev/null", rwc), rwc
 }
 
 func TestSpheroAdaptorName(t *testing.T) {
@@ -67,6 +62,28 @@ func TestSpheroAdaptor(t *testing.T) {
 	gobottest.Assert(t, a.Port(), "/dev/null")
 }
 
+func TestSpheroAdaptorArgs(t *testing.T) {
+	a := NewAdaptor("/dev/null")
+	gobottest.Assert(t, a.Baud(), 115200)
+
+	a = NewAdaptor("/dev/null", 9600)
+	gobottest.Assert(t, a.Baud(), 9600)
+
+	rwc := NewNullReadWriteCloser()
+	var written []byte
+	rwc.testAdaptorWrite = func(b []byte) (int, error) {
+		written = append(written, b...)
+		return len(b), nil
+	}
+	a = NewAdaptor("/dev/null", rwc)
+	gobottest.Assert(t, a.Port(), "/dev/null")
+	gobottest.Assert(t, a.Connect(), nil)
+	gobottest.Assert(t, a.connected, true)
+
+	a.sp.Write([]byte{0x01})
+	gobottest.Assert(t, written, []byte{0x01})
+}
+
 func TestSpheroAdaptorReconnect(t *testing.T) {
 	a, _ := initTestSpheroAdaptor()
 	a.Connect()
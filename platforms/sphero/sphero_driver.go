@@ -2,8 +2,12 @@ package sphero
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"math"
+	"reflect"
 	"sync"
 	"time"
 
@@ -19,12 +23,129 @@ const (
 
 	// Collision event when collision is detected
 	Collision = "collision"
+
+	// SelfLevelComplete event when the self level routine has finished
+	SelfLevelComplete = "selflevel"
+
+	// OrbBasic event when an orbBasic program prints a message or fails
+	OrbBasic = "orbbasic"
+
+	// Reconnecting event when the connection to the Sphero was lost, and the
+	// driver starts reconnecting, see Adaptor.KeepConnected
+	Reconnecting = "reconnecting"
+
+	// Reconnected event when the driver reconnected to the Sphero and
+	// configured it again
+	Reconnected = "reconnected"
+)
+
+// OrbBasicFragmentSize is the maximum number of bytes of orbBasic code the
+// Sphero accepts in one fragment
+const OrbBasicFragmentSize = 253
+
+// DefaultBufferSize is the default number of packets which can be queued for
+// the Sphero, and of synchronous responses waiting to be delivered
+const DefaultBufferSize = 1024
+
+// ErrSyncTimeout is the error returned when the Sphero does not answer a
+// synchronous request within the sync timeout
+var ErrSyncTimeout = errors.New("Timed out waiting for Sphero response")
+
+// ErrShortResponse is the error returned when a synchronous response is
+// shorter than the requested data
+var ErrShortResponse = errors.New("Sphero response too short")
+
+// ErrDriverHalted is the error returned for packets which were still queued,
+// or waiting to be written, when the driver was halted
+var ErrDriverHalted = errors.New("Sphero driver halted")
+
+// ErrFlushTimeout is the error returned by Flush when the packets queued for
+// the Sphero are not written within the timeout
+var ErrFlushTimeout = errors.New("Timed out flushing packets to Sphero")
+
+// haltFlushTimeout is how long Halt waits for the queued packets to be
+// written before stopping the Sphero.
+const haltFlushTimeout = time.Second
+
+// Minimum main Sphero application firmware versions of the commands which
+// older Spheros ignore
+var (
+	locatorFirmware            = [2]uint8{1, 13}
+	collisionDetectionFirmware = [2]uint8{1, 13}
+	selfLevelFirmware          = [2]uint8{1, 17}
+)
+
+// staleSequenceTimeout is how long the sequence number of a timed out
+// synchronous request is kept out of use, waiting for its late response.
+const staleSequenceTimeout = 5 * time.Second
+
+// ErrNoOrientation is the error returned when no orientation quaternion has
+// been streamed by the Sphero yet
+var ErrNoOrientation = errors.New("No orientation data streamed yet")
+
+// Configuration blocks of the Sphero, see SpheroDriver.GetConfigurationBlock
+const (
+	// ConfigBlockDefault is the factory configuration block, used while no
+	// user configuration block has been saved
+	ConfigBlockDefault uint8 = 0x00
+	// ConfigBlockUser is the configuration block saved with
+	// SpheroDriver.SetConfigurationBlock
+	ConfigBlockUser uint8 = 0x01
+
+	// ConfigBlockMaxSize is the maximum number of bytes of a configuration
+	// block that fit in one packet
+	ConfigBlockMaxSize = 254
+)
+
+const (
+	// MotionTimeoutFlag is the permanent option flag that enables the motion
+	// timeout set with SpheroDriver.SetMotionTimeout
+	MotionTimeoutFlag uint32 = 0x10
+
+	// MinInactivityTimeout is the shortest inactivity timeout in seconds
+	// accepted by the Sphero, see SpheroDriver.SetInactivityTimeout
+	MinInactivityTimeout uint16 = 60
+)
+
+// BumpBehavior is the automatic reaction of the Sphero to a collision
+// detected while rolling
+type BumpBehavior uint8
+
+const (
+	// BumpNone leaves the Sphero rolling after a collision
+	BumpNone BumpBehavior = iota
+	// BumpStop stops the Sphero briefly after a collision before resuming the roll
+	BumpStop
+	// BumpReverse rolls the Sphero in the opposite direction briefly after a
+	// collision before resuming the roll
+	BumpReverse
+)
+
+// DefaultMaxSpeed is the top speed in cm/s of a Sphero 2.0 on a hard floor,
+// used by RollMetric until SetMaxSpeed is called
+const DefaultMaxSpeed = 200.0
+
+const (
+	// RollStateStop stops the Sphero while keeping its heading
+	RollStateStop uint8 = 0x00
+	// RollStateGo rolls the Sphero at the speed and heading
+	RollStateGo uint8 = 0x01
+	// RollStateCalibrate lets the Sphero be turned by hand, see
+	// SpheroDriver.StartCalibration
+	RollStateCalibrate uint8 = 0x02
 )
 
 type packet struct {
 	header   []uint8
 	body     []uint8
 	checksum uint8
+	done     chan error
+}
+
+// isFlushMarker reports whether the packet only marks, for Flush, the end of
+// the packets queued before it, and is not to be written.
+func (p *packet) isFlushMarker() bool {
+	return p.header == nil
 }
 
 // SpheroDriver Represents a Sphero 2.0
@@ -33,10 +154,34 @@ type SpheroDriver struct {
 	connection      gobot.Connection
 	mtx             sync.Mutex
 	seq             uint8
+	bumpBehavior    BumpBehavior
+	bumpDuration    time.Duration
+	rollSpeed       uint8
+	rollHeading     uint16
+	maxSpeed        float64
+	calibration     Calibration
+	noCollisions    bool
+	rotationRate    uint8
+	streaming       *DataStreamingConfig
+	reconnecting    bool
+	done            chan struct{}
+	wg              sync.WaitGroup
+	readMtx         sync.Mutex
+	dataStreaming   *DataStreamingPacket
+	streamWindow    int
+	streamHistory   []DataStreamingPacket
+	streamDecimate  int
+	streamCount     int
+	responseMtx     sync.Mutex
 	asyncResponse   [][]uint8
-	syncResponse    [][]uint8
+	syncRequests    map[uint8]chan []uint8
+	staleSequences  map[uint8]time.Time
+	syncTimeout     time.Duration
+	syncRetries     int
 	packetChannel   chan *packet
 	responseChannel chan []uint8
+	readBuffer      []uint8
+	versioning      *Versioning
 	gobot.Eventer
 	gobot.Commander
 }
@@ -44,52 +189,113 @@ type SpheroDriver struct {
 // NewSpheroDriver returns a new SpheroDriver given a Sphero Adaptor.
 //
 // Adds the following API Commands:
-// 	"ConfigureLocator" - See SpheroDriver.ConfigureLocator
+// 	"SetRGB" - See SpheroDriver.SetRGB
+// 	"SetRGBPersist" - See SpheroDriver.SetRGBPersist
 // 	"Roll" - See SpheroDriver.Roll
+// 	"RollWithState" - See SpheroDriver.RollWithState
 // 	"Stop" - See SpheroDriver.Stop
 // 	"GetRGB" - See SpheroDriver.GetRGB
-//	"ReadLocator" - See SpheroDriver.ReadLocator
+// 	"GetVersioning" - See SpheroDriver.GetVersioning
+// 	"ReadLocator" - See SpheroDriver.ReadLocator
 // 	"SetBackLED" - See SpheroDriver.SetBackLED
+// 	"SetRotationRate" - See SpheroDriver.SetRotationRate
 // 	"SetHeading" - See SpheroDriver.SetHeading
 // 	"SetStabilization" - See SpheroDriver.SetStabilization
-//  "SetDataStreaming" - See SpheroDriver.SetDataStreaming
-//  "SetRotationRate" - See SpheroDriver.SetRotationRate
-func NewSpheroDriver(a *Adaptor) *SpheroDriver {
+// 	"SetDataStreaming" - See SpheroDriver.SetDataStreaming
+// 	"ConfigureLocator" - See SpheroDriver.ConfigureLocator
+// 	"ConfigureCollisionDetection" - See SpheroDriver.ConfigureCollisionDetection
+// 	"DisableCollisionDetection" - See SpheroDriver.DisableCollisionDetection
+// 	"StartCalibration" - See SpheroDriver.StartCalibration
+// 	"FinishCalibration" - See SpheroDriver.FinishCalibration
+// 	"SetMotionTimeout" - See SpheroDriver.SetMotionTimeout
+// 	"SetInactivityTimeout" - See SpheroDriver.SetInactivityTimeout
+// 	"SetPermanentOptionFlags" - See SpheroDriver.SetPermanentOptionFlags
+// 	"SelfLevel" - See SpheroDriver.SelfLevel
+// 	"AppendOrbBasicFragment" - See SpheroDriver.AppendOrbBasicFragment
+// 	"ExecuteOrbBasic" - See SpheroDriver.ExecuteOrbBasic
+// 	"AbortOrbBasic" - See SpheroDriver.AbortOrbBasic
+//
+// Optional params:
+//	sphero.WithPacketBufferSize(int): number of packets queued for the Sphero, DefaultBufferSize by default
+//	sphero.WithResponseBufferSize(int): number of synchronous responses waiting to be delivered, DefaultBufferSize by default
+func NewSpheroDriver(a *Adaptor, options ...func(*SpheroDriver)) *SpheroDriver {
 	s := &SpheroDriver{
 		name:            gobot.DefaultName("Sphero"),
 		connection:      a,
 		Eventer:         gobot.NewEventer(),
 		Commander:       gobot.NewCommander(),
-		packetChannel:   make(chan *packet, 1024),
-		responseChannel: make(chan []uint8, 1024),
+		packetChannel:   make(chan *packet, DefaultBufferSize),
+		responseChannel: make(chan []uint8, DefaultBufferSize),
+		syncRequests:    make(map[uint8]chan []uint8),
+		staleSequences:  make(map[uint8]time.Time),
+		syncTimeout:     50 * time.Millisecond,
+		syncRetries:     2,
+		bumpDuration:    500 * time.Millisecond,
+		maxSpeed:        DefaultMaxSpeed,
+		calibration: Calibration{
+			Locator:   DefaultLocatorConfig(),
+			Collision: DefaultCollisionConfig(),
+		},
+	}
+
+	for _, option := range options {
+		option(s)
 	}
 
 	s.AddEvent(Error)
 	s.AddEvent(Collision)
 	s.AddEvent(SensorData)
+	s.AddEvent(SelfLevelComplete)
+	s.AddEvent(OrbBasic)
+	s.AddEvent(Reconnecting)
+	s.AddEvent(Reconnected)
 
 	s.AddCommand("SetRGB", func(params map[string]interface{}) interface{} {
 		r := uint8(params["r"].(float64))
 		g := uint8(params["g"].(float64))
 		b := uint8(params["b"].(float64))
-		s.SetRGB(r, g, b)
-		return nil
+		return s.SetRGB(r, g, b)
+	})
+
+	s.AddCommand("SetRGBPersist", func(params map[string]interface{}) interface{} {
+		r := uint8(params["r"].(float64))
+		g := uint8(params["g"].(float64))
+		b := uint8(params["b"].(float64))
+		persist := params["persist"].(bool)
+		return s.SetRGBPersist(r, g, b, persist)
 	})
 
 	s.AddCommand("Roll", func(params map[string]interface{}) interface{} {
 		speed := uint8(params["speed"].(float64))
 		heading := uint16(params["heading"].(float64))
-		s.Roll(speed, heading)
-		return nil
+		return s.Roll(speed, heading)
+	})
+
+	s.AddCommand("RollWithState", func(params map[string]interface{}) interface{} {
+		speed := uint8(params["speed"].(float64))
+		heading := uint16(params["heading"].(float64))
+		state := uint8(params["state"].(float64))
+		return s.RollWithState(speed, heading, state)
 	})
 
 	s.AddCommand("Stop", func(params map[string]interface{}) interface{} {
-		s.Stop()
-		return nil
+		return s.Stop()
 	})
 
 	s.AddCommand("GetRGB", func(params map[string]interface{}) interface{} {
-		return s.GetRGB()
+		r, g, b, err := s.GetRGB()
+		if err != nil {
+			return err
+		}
+		return []uint8{r, g, b}
+	})
+
+	s.AddCommand("GetVersioning", func(params map[string]interface{}) interface{} {
+		v, err := s.GetVersioning()
+		if err != nil {
+			return err
+		}
+		return v
 	})
 
 	s.AddCommand("ReadLocator", func(params map[string]interface{}) interface{} {
@@ -98,26 +304,25 @@ func NewSpheroDriver(a *Adaptor) *SpheroDriver {
 
 	s.AddCommand("SetBackLED", func(params map[string]interface{}) interface{} {
 		level := uint8(params["level"].(float64))
-		s.SetBackLED(level)
-		return nil
+		return s.SetBackLED(level)
 	})
 
 	s.AddCommand("SetRotationRate", func(params map[string]interface{}) interface{} {
-		level := uint8(params["level"].(float64))
-		s.SetRotationRate(level)
-		return nil
+		level, ok := params["level"].(float64)
+		if !ok || level < 0 || level > 255 {
+			return errors.New("Rotation rate level must be between 0 and 255")
+		}
+		return s.SetRotationRate(uint8(level))
 	})
 
 	s.AddCommand("SetHeading", func(params map[string]interface{}) interface{} {
 		heading := uint16(params["heading"].(float64))
-		s.SetHeading(heading)
-		return nil
+		return s.SetHeading(heading)
 	})
 
 	s.AddCommand("SetStabilization", func(params map[string]interface{}) interface{} {
 		on := params["enable"].(bool)
-		s.SetStabilization(on)
-		return nil
+		return s.SetStabilization(on)
 	})
 
 	s.AddCommand("SetDataStreaming", func(params map[string]interface{}) interface{} {
@@ -127,8 +332,7 @@ func NewSpheroDriver(a *Adaptor) *SpheroDriver {
 		Pcnt := uint8(params["Pcnt"].(float64))
 		Mask2 := uint32(params["Mask2"].(float64))
 
-		s.SetDataStreaming(DataStreamingConfig{N: N, M: M, Mask2: Mask2, Pcnt: Pcnt, Mask: Mask})
-		return nil
+		return s.SetDataStreaming(DataStreamingConfig{N: N, M: M, Mask2: Mask2, Pcnt: Pcnt, Mask: Mask})
 	})
 
 	s.AddCommand("ConfigureLocator", func(params map[string]interface{}) interface{} {
@@ -137,13 +341,114 @@ func NewSpheroDriver(a *Adaptor) *SpheroDriver {
 		Y := int16(params["Y"].(float64))
 		YawTare := int16(params["YawTare"].(float64))
 
-		s.ConfigureLocator(LocatorConfig{Flags: Flags, X: X, Y: Y, YawTare: YawTare})
-		return nil
+		return s.ConfigureLocator(LocatorConfig{Flags: Flags, X: X, Y: Y, YawTare: YawTare})
+	})
+
+	s.AddCommand("ConfigureCollisionDetection", func(params map[string]interface{}) interface{} {
+		var fields [6]uint8
+		for i, name := range []string{"Method", "Xt", "Yt", "Xs", "Ys", "Dead"} {
+			value, ok := params[name].(float64)
+			if !ok {
+				return errors.New("Missing collision detection parameter " + name)
+			}
+			fields[i] = uint8(value)
+		}
+
+		return s.ConfigureCollisionDetection(CollisionConfig{
+			Method: fields[0],
+			Xt:     fields[1],
+			Yt:     fields[2],
+			Xs:     fields[3],
+			Ys:     fields[4],
+			Dead:   fields[5],
+		})
+	})
+
+	s.AddCommand("DisableCollisionDetection", func(params map[string]interface{}) interface{} {
+		return s.DisableCollisionDetection()
+	})
+
+	s.AddCommand("StartCalibration", func(params map[string]interface{}) interface{} {
+		return s.StartCalibration()
+	})
+
+	s.AddCommand("FinishCalibration", func(params map[string]interface{}) interface{} {
+		heading, ok := params["heading"].(float64)
+		if !ok {
+			return errors.New("Missing calibration parameter heading")
+		}
+		return s.FinishCalibration(uint16(heading))
+	})
+
+	s.AddCommand("SetMotionTimeout", func(params map[string]interface{}) interface{} {
+		timeout := uint16(params["timeout"].(float64))
+		return s.SetMotionTimeout(timeout)
+	})
+
+	s.AddCommand("SetInactivityTimeout", func(params map[string]interface{}) interface{} {
+		seconds := uint16(params["seconds"].(float64))
+		return s.SetInactivityTimeout(seconds)
+	})
+
+	s.AddCommand("SetPermanentOptionFlags", func(params map[string]interface{}) interface{} {
+		flags := uint32(params["flags"].(float64))
+		return s.SetPermanentOptionFlags(flags)
+	})
+
+	s.AddCommand("SelfLevel", func(params map[string]interface{}) interface{} {
+		options := uint8(params["options"].(float64))
+		angleLimit := uint8(params["angleLimit"].(float64))
+		timeout := uint8(params["timeout"].(float64))
+		trueTime := uint8(params["trueTime"].(float64))
+		return s.SelfLevel(options, angleLimit, timeout, trueTime)
+	})
+
+	s.AddCommand("AppendOrbBasicFragment", func(params map[string]interface{}) interface{} {
+		area := uint8(params["area"].(float64))
+		code := params["code"].(string)
+		return s.AppendOrbBasicFragment(area, []byte(code))
+	})
+
+	s.AddCommand("ExecuteOrbBasic", func(params map[string]interface{}) interface{} {
+		area := uint8(params["area"].(float64))
+		startLine := uint16(params["startLine"].(float64))
+		return s.ExecuteOrbBasic(area, startLine)
+	})
+
+	s.AddCommand("AbortOrbBasic", func(params map[string]interface{}) interface{} {
+		return s.AbortOrbBasic()
 	})
 
 	return s
 }
 
+// WithPacketBufferSize option sets how many packets can be queued for the
+// Sphero before the commands sending them block, at least 1. Commands sent
+// faster than the serial port writes them, e.g. from several goroutines,
+// need a deeper buffer, while low-memory targets may want a smaller one.
+func WithPacketBufferSize(size int) func(*SpheroDriver) {
+	return func(s *SpheroDriver) {
+		if size < 1 {
+			size = 1
+		}
+		s.packetChannel = make(chan *packet, size)
+	}
+}
+
+// WithResponseBufferSize option sets how many synchronous responses read
+// from the Sphero can wait to be delivered before reading stops, at least 1.
+// The data streaming packets, sent asynchronously at up to 400Hz, are not
+// buffered there, so that only the number of synchronous requests pending
+// at once, one per sending goroutine, needs to fit.
+func WithResponseBufferSize(size int) func(*SpheroDriver) {
+	return func(s *SpheroDriver) {
+		if size < 1 {
+			size = 1
+		}
+		s.responseChannel = make(chan []uint8, size)
+	}
+}
+
 // Name returns the Driver Name
 func (s *SpheroDriver) Name() string { return s.name }
 
@@ -157,102 +462,319 @@ func (s *SpheroDriver) adaptor() *Adaptor {
 	return s.Connection().(*Adaptor)
 }
 
-// Start starts the SpheroDriver and enables Collision Detection.
+// Start starts the SpheroDriver and enables Collision Detection, unless it
+// was disabled with DisableCollisionDetection.
 // Returns true on successful start.
 //
 // Emits the Events:
 // 	Collision  sphero.CollisionPacket - On Collision Detected
 // 	SensorData sphero.DataStreamingPacket - On Data Streaming event
+// 	SelfLevelComplete uint8 - On self level finished, with the result code
 // 	Error      error- On error while processing asynchronous response
+// 	Reconnecting error - On connection lost, with the error, when the Adaptor keeps connected
+// 	Reconnected - On reconnected to the Sphero
 func (s *SpheroDriver) Start() (err error) {
+	return s.StartContext(context.Background())
+}
+
+// StartContext starts the SpheroDriver like Start, and halts it once ctx is
+// done, stopping the Sphero and the goroutines started.
+func (s *SpheroDriver) StartContext(ctx context.Context) (err error) {
+	s.mtx.Lock()
+	s.done = make(chan struct{})
+	done := s.done
+	s.mtx.Unlock()
+
+	// not waited for by Halt, which it calls
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				s.Halt()
+			case <-done:
+			}
+		}()
+	}
+
+	s.wg.Add(3)
+
 	go func() {
+		defer s.wg.Done()
 		for {
-			packet := <-s.packetChannel
-			err := s.write(packet)
-			if err != nil {
-				s.Publish(Error, err)
+			select {
+			case packet := <-s.packetChannel:
+				select {
+				case <-done:
+					packet.done <- ErrDriverHalted
+					s.failQueuedPackets()
+					return
+				default:
+				}
+				if packet.isFlushMarker() {
+					packet.done <- nil
+					continue
+				}
+				err := s.write(packet)
+				if err != nil {
+					s.Publish(Error, err)
+					s.connectionLost(err)
+				}
+				packet.done <- err
+			case <-done:
+				s.failQueuedPackets()
+				return
 			}
 		}
 	}()
 
 	go func() {
+		defer s.wg.Done()
 		for {
-			response := <-s.responseChannel
-			s.syncResponse = append(s.syncResponse, response)
+			select {
+			case response := <-s.responseChannel:
+				s.deliverSyncResponse(response)
+			case <-done:
+				return
+			}
 		}
 	}()
 
+	// the reader is not waited for by Halt, as it may be blocked reading until
+	// the Adaptor closes the port. It exits once its pending read returns.
 	go func() {
 		for {
-			header := s.readHeader()
-			if len(header) > 0 {
-				body := s.readBody(header[4])
-				data := append(header, body...)
-				checksum := data[len(data)-1]
-				if checksum != calculateChecksum(data[2:len(data)-1]) {
-					continue
-				}
-				switch header[1] {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			s.readMtx.Lock()
+			data := s.readPacket(done)
+			s.readMtx.Unlock()
+			if data != nil {
+				switch data[1] {
 				case 0xFE:
+					s.responseMtx.Lock()
 					s.asyncResponse = append(s.asyncResponse, data)
+					s.responseMtx.Unlock()
 				case 0xFF:
-					s.responseChannel <- data
+					select {
+					case s.responseChannel <- data:
+					case <-done:
+						return
+					}
 				}
 			}
 		}
 	}()
 
 	go func() {
+		defer s.wg.Done()
 		for {
-			var evt []uint8
-			for len(s.asyncResponse) != 0 {
-				evt, s.asyncResponse = s.asyncResponse[len(s.asyncResponse)-1], s.asyncResponse[:len(s.asyncResponse)-1]
+			for evt := s.popAsyncResponse(); evt != nil; evt = s.popAsyncResponse() {
 				if evt[2] == 0x07 {
 					s.handleCollisionDetected(evt)
 				} else if evt[2] == 0x03 {
 					s.handleDataStreaming(evt)
+				} else if evt[2] == 0x0B {
+					s.handleSelfLevelComplete(evt)
+				} else if evt[2] == 0x08 || evt[2] == 0x09 {
+					s.handleOrbBasic(evt)
 				}
 			}
-			time.Sleep(100 * time.Millisecond)
+			select {
+			case <-time.After(100 * time.Millisecond):
+			case <-done:
+				return
+			}
 		}
 	}()
 
-	s.ConfigureCollisionDetection(DefaultCollisionConfig())
-	s.enableStopOnDisconnect()
-
-	return
+	s.mtx.Lock()
+	noCollisions := s.noCollisions
+	s.mtx.Unlock()
+	if !noCollisions {
+		if err = s.ConfigureCollisionDetection(DefaultCollisionConfig()); err != nil {
+			return
+		}
+	}
+	return s.enableStopOnDisconnect()
 }
 
 // Halt halts the SpheroDriver and sends a SpheroDriver.Stop command to the Sphero.
-// Returns true on successful halt.
+// It first flushes the packets already queued, so that the last commands
+// sent reach the Sphero. It then stops the goroutines started by Start, and
+// returns once the ones writing to the Sphero and handling its responses
+// have exited. Packets still queued fail with ErrDriverHalted.
 func (s *SpheroDriver) Halt() (err error) {
-	if s.adaptor().connected {
-		gobot.Every(10*time.Millisecond, func() {
-			s.Stop()
-		})
-		time.Sleep(1 * time.Second)
+	if _, connected := s.adaptor().connection(); connected {
+		if s.started() {
+			err = s.Flush(haltFlushTimeout)
+		}
+		if stopErr := s.Stop(); err == nil {
+			err = stopErr
+		}
+	}
+
+	s.mtx.Lock()
+	done := s.done
+	s.done = nil
+	s.mtx.Unlock()
+
+	if done != nil {
+		close(done)
+		s.wg.Wait()
 	}
 	return
 }
 
-// SetRGB sets the Sphero to the given r, g, and b values
-func (s *SpheroDriver) SetRGB(r uint8, g uint8, b uint8) {
-	s.packetChannel <- s.craftPacket([]uint8{r, g, b, 0x01}, 0x02, 0x20)
+// SetRGB sets the Sphero to the given r, g, and b values, and saves them as
+// the user LED color, see SetRGBPersist.
+func (s *SpheroDriver) SetRGB(r uint8, g uint8, b uint8) (err error) {
+	return s.SetRGBPersist(r, g, b, true)
 }
 
-// GetRGB returns the current r, g, b value of the Sphero
-func (s *SpheroDriver) GetRGB() []uint8 {
-	buf := s.getSyncResponse(s.craftPacket([]uint8{}, 0x02, 0x22))
-	if len(buf) == 9 {
-		return []uint8{buf[5], buf[6], buf[7]}
+// SetRGBPersist sets the Sphero to the given r, g, and b values. When
+// persist is true they are also saved as the user LED color, which the
+// Sphero shows after a reset and GetRGB returns; otherwise the color is only
+// shown until the next one, such as a flash of feedback.
+func (s *SpheroDriver) SetRGBPersist(r uint8, g uint8, b uint8, persist bool) (err error) {
+	flag := uint8(0x00)
+	if persist {
+		flag = 0x01
 	}
-	return []uint8{}
+	return s.sendPacket(s.craftPacket([]uint8{r, g, b, flag}, 0x02, 0x20))
+}
+
+// GetRGB returns the user LED color of the Sphero, saved by SetRGB, rather
+// than the color shown after a transient SetRGBPersist, as the Sphero has no
+// command to read back the live color. A request which is not answered is
+// retried, see SetSyncRetries, before returning ErrSyncTimeout.
+func (s *SpheroDriver) GetRGB() (r, g, b uint8, err error) {
+	s.responseMtx.Lock()
+	retries := s.syncRetries
+	s.responseMtx.Unlock()
+
+	var buf []byte
+	for i := 0; i <= retries; i++ {
+		if buf, err = s.getSyncResponse(s.craftPacket([]uint8{}, 0x02, 0x22)); err != ErrSyncTimeout {
+			break
+		}
+	}
+	if err != nil {
+		return
+	}
+	// header, r, g, b and checksum
+	if len(buf) < 9 {
+		return 0, 0, 0, ErrShortResponse
+	}
+	return buf[5], buf[6], buf[7], nil
+}
+
+// GetVersioning reads the model and the hardware and firmware versions of
+// the Sphero. They are only read once, and then returned from the cache.
+func (s *SpheroDriver) GetVersioning() (Versioning, error) {
+	s.mtx.Lock()
+	cached := s.versioning
+	s.mtx.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+
+	buf, err := s.getSyncResponse(s.craftPacket([]uint8{}, 0x00, 0x02))
+	if err != nil {
+		return Versioning{}, err
+	}
+	// header, 8 bytes of versioning data and checksum
+	if len(buf) < 14 {
+		return Versioning{}, ErrShortResponse
+	}
+	v := Versioning{
+		RecordVersion:     buf[5],
+		ModelNumber:       buf[6],
+		HardwareVersion:   buf[7],
+		MSAVersionMajor:   buf[8],
+		MSAVersionMinor:   buf[9],
+		BootloaderVersion: buf[10],
+		OrbBasicVersion:   buf[11],
+		MacroVersion:      buf[12],
+	}
+	s.mtx.Lock()
+	s.versioning = &v
+	s.mtx.Unlock()
+	return v, nil
+}
+
+// SupportsLocator reports whether the firmware of the Sphero supports
+// ConfigureLocator and ReadLocator.
+func (s *SpheroDriver) SupportsLocator() (bool, error) {
+	return s.supportsFirmware(locatorFirmware)
+}
+
+// SupportsCollisionDetection reports whether the firmware of the Sphero
+// supports ConfigureCollisionDetection.
+func (s *SpheroDriver) SupportsCollisionDetection() (bool, error) {
+	return s.supportsFirmware(collisionDetectionFirmware)
+}
+
+// SupportsSelfLevel reports whether the firmware of the Sphero supports
+// SelfLevel.
+func (s *SpheroDriver) SupportsSelfLevel() (bool, error) {
+	return s.supportsFirmware(selfLevelFirmware)
+}
+
+// SupportsOrbBasic reports whether the Sphero has an orbBasic interpreter.
+func (s *SpheroDriver) SupportsOrbBasic() (bool, error) {
+	v, err := s.GetVersioning()
+	return v.OrbBasicVersion != 0, err
+}
+
+// supportsFirmware reports whether the main Sphero application firmware is
+// at least the major and minor version.
+func (s *SpheroDriver) supportsFirmware(version [2]uint8) (bool, error) {
+	v, err := s.GetVersioning()
+	if err != nil {
+		return false, err
+	}
+	return v.MSAVersionAtLeast(version[0], version[1]), nil
+}
+
+// GetConfigurationBlock reads the configuration block id, ConfigBlockDefault
+// or ConfigBlockUser, from the flash of the Sphero. It holds settings such as
+// the trim of the motors and the option flags, kept across power cycles.
+func (s *SpheroDriver) GetConfigurationBlock(id uint8) ([]byte, error) {
+	if id != ConfigBlockDefault && id != ConfigBlockUser {
+		return nil, fmt.Errorf("Invalid configuration block %d, must be %d or %d", id, ConfigBlockDefault, ConfigBlockUser)
+	}
+
+	buf, err := s.getSyncResponse(s.craftPacket([]uint8{id}, 0x02, 0x40))
+	if err != nil {
+		return nil, err
+	}
+	// header, at least one byte of the block and checksum
+	if len(buf) < 7 {
+		return nil, ErrShortResponse
+	}
+	return append([]byte{}, buf[5:len(buf)-1]...), nil
+}
+
+// SetConfigurationBlock writes block, as previously read with
+// GetConfigurationBlock and modified, as the user configuration block to the
+// flash of the Sphero. The block must hold between 1 and ConfigBlockMaxSize
+// bytes.
+func (s *SpheroDriver) SetConfigurationBlock(block []byte) (err error) {
+	if len(block) == 0 || len(block) > ConfigBlockMaxSize {
+		return fmt.Errorf("Invalid configuration block length %d, must be between 1 and %d", len(block), ConfigBlockMaxSize)
+	}
+	_, err = s.getSyncResponse(s.craftPacket(append([]uint8{}, block...), 0x02, 0x43))
+	return
 }
 
 // ReadLocator reads Sphero's current position (X,Y), component velocities and SOG (speed over ground).
 func (s *SpheroDriver) ReadLocator() []int16 {
-	buf := s.getSyncResponse(s.craftPacket([]uint8{}, 0x02, 0x15))
-	if len(buf) == 16 {
+	buf, err := s.getSyncResponse(s.craftPacket([]uint8{}, 0x02, 0x15))
+	if err == nil && len(buf) == 16 {
 		vals := make([]int16, 5)
 		_ = binary.Read(bytes.NewReader(buf[5:15]), binary.BigEndian, &vals)
 		return vals
@@ -261,63 +783,395 @@ func (s *SpheroDriver) ReadLocator() []int16 {
 }
 
 // SetBackLED sets the Sphero Back LED to the specified brightness
-func (s *SpheroDriver) SetBackLED(level uint8) {
-	s.packetChannel <- s.craftPacket([]uint8{level}, 0x02, 0x21)
+func (s *SpheroDriver) SetBackLED(level uint8) (err error) {
+	return s.sendPacket(s.craftPacket([]uint8{level}, 0x02, 0x21))
 }
 
-// SetRotationRate sets the Sphero rotation rate
-// A value of 255 jumps to the maximum (currently 400 degrees/sec).
-func (s *SpheroDriver) SetRotationRate(level uint8) {
-	s.packetChannel <- s.craftPacket([]uint8{level}, 0x02, 0x03)
+// SetRotationRate sets how fast the Sphero turns to a new heading, in steps
+// of about 0.784 degrees/sec. A value of 0 still turns, just very slowly, and
+// a value of 255 jumps to the maximum (currently 400 degrees/sec).
+// The Sphero does not keep the rate across power cycles, see RotationRate.
+func (s *SpheroDriver) SetRotationRate(level uint8) (err error) {
+	s.mtx.Lock()
+	s.rotationRate = level
+	s.mtx.Unlock()
+	return s.sendPacket(s.craftPacket([]uint8{level}, 0x02, 0x03))
+}
+
+// RotationRate returns the rotation rate last set with SetRotationRate, to
+// apply it again after a reconnect.
+func (s *SpheroDriver) RotationRate() uint8 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.rotationRate
 }
 
 // SetHeading sets the heading of the Sphero
-func (s *SpheroDriver) SetHeading(heading uint16) {
-	s.packetChannel <- s.craftPacket([]uint8{uint8(heading >> 8), uint8(heading & 0xFF)}, 0x02, 0x01)
+func (s *SpheroDriver) SetHeading(heading uint16) (err error) {
+	s.mtx.Lock()
+	s.calibration.Heading = heading
+	s.mtx.Unlock()
+	return s.sendPacket(s.craftPacket([]uint8{uint8(heading >> 8), uint8(heading & 0xFF)}, 0x02, 0x01))
 }
 
 // SetStabilization enables or disables the built-in auto stabilizing features of the Sphero
-func (s *SpheroDriver) SetStabilization(on bool) {
+func (s *SpheroDriver) SetStabilization(on bool) (err error) {
 	b := uint8(0x01)
 	if !on {
 		b = 0x00
 	}
-	s.packetChannel <- s.craftPacket([]uint8{b}, 0x02, 0x02)
+	return s.sendPacket(s.craftPacket([]uint8{b}, 0x02, 0x02))
+}
+
+// Roll sends a roll command to the Sphero gives a speed and heading. The
+// speed goes from 0, stopped, to 255, the top speed of the Sphero, and is
+// roughly proportional to the power of the motors rather than to the actual
+// speed, see RollMetric. The heading is in degrees, taken modulo 360.
+func (s *SpheroDriver) Roll(speed uint8, heading uint16) (err error) {
+	return s.RollWithState(speed, heading, RollStateGo)
+}
+
+// RollWithState sends a roll command to the Sphero with the state of the
+// roll, one of RollStateStop, RollStateGo or RollStateCalibrate. Unlike
+// Stop, RollStateStop brings the Sphero to a halt facing heading.
+func (s *SpheroDriver) RollWithState(speed uint8, heading uint16, state uint8) (err error) {
+	if state > RollStateCalibrate {
+		return fmt.Errorf("Invalid roll state %d, must be between 0 and 2", state)
+	}
+
+	heading %= 360
+
+	s.mtx.Lock()
+	if state == RollStateGo {
+		s.rollSpeed, s.rollHeading = speed, heading
+	} else {
+		s.rollSpeed, s.rollHeading = 0, heading
+	}
+	s.mtx.Unlock()
+	return s.rollState(speed, heading, state)
+}
+
+// RollMetric rolls the Sphero at cmPerSec toward headingDeg degrees, taken
+// modulo 360. The speed is scaled to the 0-255 Roll speed from the top speed
+// of the Sphero, see SetMaxSpeed, and clamped to it.
+func (s *SpheroDriver) RollMetric(cmPerSec float64, headingDeg float64) (err error) {
+	if cmPerSec < 0 || math.IsNaN(cmPerSec) || math.IsInf(cmPerSec, 0) {
+		return fmt.Errorf("Invalid speed %v cm/s, must be finite and not negative", cmPerSec)
+	}
+	if math.IsNaN(headingDeg) || math.IsInf(headingDeg, 0) {
+		return fmt.Errorf("Invalid heading %v, must be finite", headingDeg)
+	}
+
+	s.mtx.Lock()
+	maxSpeed := s.maxSpeed
+	s.mtx.Unlock()
+
+	speed := math.Min(cmPerSec/maxSpeed*255, 255)
+	heading := math.Mod(headingDeg, 360)
+	if heading < 0 {
+		heading += 360
+	}
+	return s.Roll(uint8(math.Floor(speed+0.5)), uint16(math.Floor(heading+0.5)))
+}
+
+// SetMaxSpeed sets the top speed in cm/s of the Sphero, reached at a Roll
+// speed of 255, to calibrate RollMetric. It depends on the floor and on the
+// charge of the battery, DefaultMaxSpeed is used until it is set. It returns
+// an error unless cmPerSec is finite and positive.
+func (s *SpheroDriver) SetMaxSpeed(cmPerSec float64) (err error) {
+	if cmPerSec <= 0 || math.IsNaN(cmPerSec) || math.IsInf(cmPerSec, 0) {
+		return fmt.Errorf("Invalid top speed %v cm/s, must be finite and positive", cmPerSec)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.maxSpeed = cmPerSec
+	return
+}
+
+func (s *SpheroDriver) roll(speed uint8, heading uint16) (err error) {
+	return s.rollState(speed, heading, RollStateGo)
+}
+
+func (s *SpheroDriver) rollState(speed uint8, heading uint16, state uint8) (err error) {
+	return s.sendPacket(s.craftPacket([]uint8{speed, uint8(heading >> 8), uint8(heading & 0xFF), state}, 0x02, 0x30))
+}
+
+// StartCalibration turns on the back LED and lets the Sphero be turned by
+// hand, so its back LED can be pointed away from the desired forward
+// direction. Call FinishCalibration once it is aimed.
+func (s *SpheroDriver) StartCalibration() (err error) {
+	if err = s.SetBackLED(255); err != nil {
+		return
+	}
+	return s.rollState(0, 0, RollStateCalibrate)
+}
+
+// FinishCalibration sets the current direction of the Sphero as the given
+// heading and turns off the back LED, which is turned off even if setting
+// the heading fails.
+func (s *SpheroDriver) FinishCalibration(heading uint16) (err error) {
+	err = s.SetHeading(heading)
+	if ledErr := s.SetBackLED(0); err == nil {
+		err = ledErr
+	}
+	return
+}
+
+// SetBumpBehavior sets how the Sphero reacts on its own to a collision
+// detected while rolling. BumpStop and BumpReverse resume the last Roll
+// once the reaction is over. The default is BumpNone.
+func (s *SpheroDriver) SetBumpBehavior(b BumpBehavior) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.bumpBehavior = b
+}
+
+// SetMotionTimeout sets the time in milliseconds after which the Sphero stops
+// if no new motion command has been received, and enables the motion timeout
+// permanent option flag, as the feature is disabled on the Sphero by default.
+// The other permanent option flags of the Sphero are kept.
+func (s *SpheroDriver) SetMotionTimeout(ms uint16) (err error) {
+	if err = s.sendPacket(s.craftPacket([]uint8{uint8(ms >> 8), uint8(ms & 0xFF)}, 0x02, 0x34)); err != nil {
+		return
+	}
+	flags, err := s.GetPermanentOptionFlags()
+	if err != nil || flags&MotionTimeoutFlag != 0 {
+		return
+	}
+	return s.SetPermanentOptionFlags(flags | MotionTimeoutFlag)
+}
+
+// SetInactivityTimeout sets the time in seconds without any command after
+// which the Sphero goes to sleep, 600 seconds by default. The timer restarts
+// on every command received. Timeouts below MinInactivityTimeout are raised
+// to it.
+func (s *SpheroDriver) SetInactivityTimeout(seconds uint16) (err error) {
+	if seconds < MinInactivityTimeout {
+		seconds = MinInactivityTimeout
+	}
+	return s.sendPacket(s.craftPacket([]uint8{uint8(seconds >> 8), uint8(seconds & 0xFF)}, 0x02, 0x25))
+}
+
+// GetPermanentOptionFlags reads the option flags that the Sphero keeps
+// across power cycles.
+func (s *SpheroDriver) GetPermanentOptionFlags() (uint32, error) {
+	buf, err := s.getSyncResponse(s.craftPacket([]uint8{}, 0x02, 0x36))
+	if err != nil {
+		return 0, err
+	}
+	// header, 4 bytes of flags and checksum
+	if len(buf) < 10 {
+		return 0, ErrShortResponse
+	}
+	return binary.BigEndian.Uint32(buf[5:9]), nil
+}
+
+// SetPermanentOptionFlags sets the option flags that the Sphero keeps
+// across power cycles, such as MotionTimeoutFlag. All of the flags are
+// written, read them with GetPermanentOptionFlags to change only some.
+func (s *SpheroDriver) SetPermanentOptionFlags(flags uint32) (err error) {
+	return s.sendPacket(s.craftPacket([]uint8{uint8(flags >> 24), uint8(flags >> 16), uint8(flags >> 8), uint8(flags)}, 0x02, 0x35))
+}
+
+// SelfLevel starts the self level routine, which levels the Sphero on a flat
+// surface so its orientation can be reset. The angleLimit is in degrees, the
+// timeout in seconds and the trueTime in tenths of a second; a value of 0
+// uses the Sphero defaults. A SelfLevelComplete event is published when done.
+func (s *SpheroDriver) SelfLevel(options uint8, angleLimit uint8, timeout uint8, trueTime uint8) (err error) {
+	return s.sendPacket(s.craftPacket([]uint8{options, angleLimit, timeout, trueTime}, 0x02, 0x09))
+}
+
+// AppendOrbBasicFragment appends orbBasic code to the program stored in the
+// given area of the Sphero. Code longer than OrbBasicFragmentSize is sent in
+// several fragments.
+func (s *SpheroDriver) AppendOrbBasicFragment(area uint8, code []byte) (err error) {
+	for len(code) > 0 {
+		n := len(code)
+		if n > OrbBasicFragmentSize {
+			n = OrbBasicFragmentSize
+		}
+		body := append([]uint8{area}, code[:n]...)
+		if err = s.sendPacket(s.craftPacket(body, 0x02, 0x58)); err != nil {
+			return
+		}
+		code = code[n:]
+	}
+	return
+}
+
+// ExecuteOrbBasic runs the orbBasic program stored in the given area, from
+// the given line number. Messages printed by the program and errors are
+// published as OrbBasic events.
+func (s *SpheroDriver) ExecuteOrbBasic(area uint8, startLine uint16) (err error) {
+	return s.sendPacket(s.craftPacket([]uint8{area, uint8(startLine >> 8), uint8(startLine)}, 0x02, 0x5B))
 }
 
-// Roll sends a roll command to the Sphero gives a speed and heading
-func (s *SpheroDriver) Roll(speed uint8, heading uint16) {
-	s.packetChannel <- s.craftPacket([]uint8{speed, uint8(heading >> 8), uint8(heading & 0xFF), 0x01}, 0x02, 0x30)
+// AbortOrbBasic stops the orbBasic program running on the Sphero
+func (s *SpheroDriver) AbortOrbBasic() (err error) {
+	return s.sendPacket(s.craftPacket([]uint8{}, 0x02, 0x5C))
 }
 
 // ConfigureLocator configures and enables the Locator
-func (s *SpheroDriver) ConfigureLocator(d LocatorConfig) {
+func (s *SpheroDriver) ConfigureLocator(d LocatorConfig) (err error) {
+	s.mtx.Lock()
+	s.calibration.Locator = d
+	s.mtx.Unlock()
+
 	buf := new(bytes.Buffer)
 	binary.Write(buf, binary.BigEndian, d)
 
-	s.packetChannel <- s.craftPacket(buf.Bytes(), 0x02, 0x13)
+	return s.sendPacket(s.craftPacket(buf.Bytes(), 0x02, 0x13))
 }
 
-// SetDataStreaming enables sensor data streaming
-func (s *SpheroDriver) SetDataStreaming(d DataStreamingConfig) {
+// SetDataStreaming enables sensor data streaming. The configuration is sent
+// again when the driver reconnects to the Sphero.
+func (s *SpheroDriver) SetDataStreaming(d DataStreamingConfig) (err error) {
+	s.mtx.Lock()
+	s.streaming = &d
+	s.mtx.Unlock()
+
 	buf := new(bytes.Buffer)
 	binary.Write(buf, binary.BigEndian, d)
 
-	s.packetChannel <- s.craftPacket(buf.Bytes(), 0x02, 0x11)
+	return s.sendPacket(s.craftPacket(buf.Bytes(), 0x02, 0x11))
+}
+
+// SetDataStreamingSensors enables the streaming of the sensors of mask,
+// sampled at freq Hz, from 1 to 400, with frames sample frames per packet.
+func (s *SpheroDriver) SetDataStreamingSensors(freq, frames uint16, mask SensorMask) (err error) {
+	if freq < 1 || freq > 400 {
+		return fmt.Errorf("Invalid data streaming frequency %dHz, must be between 1 and 400Hz", freq)
+	}
+	if frames < 1 {
+		return errors.New("Invalid data streaming frames, must be at least 1")
+	}
+
+	m, m2 := mask.Masks()
+	return s.SetDataStreaming(DataStreamingConfig{N: 400 / freq, M: frames, Mask: m, Mask2: m2})
 }
 
 // Stop sets the Sphero to a roll speed of 0
-func (s *SpheroDriver) Stop() {
-	s.Roll(0, 0)
+func (s *SpheroDriver) Stop() (err error) {
+	return s.Roll(0, 0)
 }
 
 // ConfigureCollisionDetection configures the sensitivity of the detection.
-func (s *SpheroDriver) ConfigureCollisionDetection(cc CollisionConfig) {
-	s.packetChannel <- s.craftPacket([]uint8{cc.Method, cc.Xt, cc.Yt, cc.Xs, cc.Ys, cc.Dead}, 0x02, 0x12)
+// A Method of 0 disables the detection.
+func (s *SpheroDriver) ConfigureCollisionDetection(cc CollisionConfig) (err error) {
+	s.mtx.Lock()
+	s.calibration.Collision = cc
+	s.noCollisions = cc.Method == 0
+	s.mtx.Unlock()
+	return s.sendPacket(s.craftPacket([]uint8{cc.Method, cc.Xt, cc.Yt, cc.Xs, cc.Ys, cc.Dead}, 0x02, 0x12))
 }
 
-func (s *SpheroDriver) enableStopOnDisconnect() {
-	s.packetChannel <- s.craftPacket([]uint8{0x00, 0x00, 0x00, 0x01}, 0x02, 0x37)
+// DisableCollisionDetection turns off the collision detection, no more
+// Collision events are published until it is configured again. Start does
+// not enable it while it is disabled.
+func (s *SpheroDriver) DisableCollisionDetection() (err error) {
+	return s.ConfigureCollisionDetection(CollisionConfig{Method: 0x00})
+}
+
+// SaveCalibration returns the locator, heading and collision detection
+// configuration last sent to the Sphero.
+func (s *SpheroDriver) SaveCalibration() Calibration {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.calibration
+}
+
+// RestoreCalibration sends a Calibration previously returned by
+// SaveCalibration to the Sphero, for example after a reconnect.
+func (s *SpheroDriver) RestoreCalibration(c Calibration) (err error) {
+	if err = s.ConfigureLocator(c.Locator); err != nil {
+		return
+	}
+	if err = s.SetHeading(c.Heading); err != nil {
+		return
+	}
+	return s.ConfigureCollisionDetection(c.Collision)
+}
+
+// connectionLost starts reconnecting to the Sphero after reading from or
+// writing to it failed with err, when the Adaptor keeps connected. Failures
+// while the driver is halted, already reconnecting or disconnected are
+// ignored.
+func (s *SpheroDriver) connectionLost(err error) {
+	a := s.adaptor()
+	if !a.KeepConnected {
+		return
+	}
+	if _, connected := a.connection(); !connected {
+		return
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.done == nil || s.reconnecting {
+		return
+	}
+	s.reconnecting = true
+	go s.reconnect(err, s.done)
+}
+
+// reconnect reconnects to the Sphero, with exponential backoff between
+// attempts, until it succeeds or done is closed, then configures the Sphero
+// again with the collision detection, data streaming and calibration.
+func (s *SpheroDriver) reconnect(cause error, done chan struct{}) {
+	defer func() {
+		s.mtx.Lock()
+		s.reconnecting = false
+		s.mtx.Unlock()
+	}()
+
+	s.Publish(Reconnecting, cause)
+	a := s.adaptor()
+	wait := a.ReconnectInterval
+	for {
+		select {
+		case <-time.After(wait):
+		case <-done:
+			return
+		}
+
+		err := a.Reconnect()
+		if err == nil {
+			break
+		}
+		s.Publish(Error, err)
+
+		if wait *= 2; wait > a.MaxReconnectInterval {
+			wait = a.MaxReconnectInterval
+		}
+	}
+
+	if err := s.reconfigure(); err != nil {
+		s.Publish(Error, err)
+	}
+	s.Publish(Reconnected, nil)
+}
+
+// reconfigure sends the configuration the Sphero loses when the connection
+// drops.
+func (s *SpheroDriver) reconfigure() (err error) {
+	s.mtx.Lock()
+	calibration, streaming := s.calibration, s.streaming
+	s.mtx.Unlock()
+
+	if err = s.RestoreCalibration(calibration); err != nil {
+		return
+	}
+	if streaming != nil {
+		if err = s.SetDataStreaming(*streaming); err != nil {
+			return
+		}
+	}
+	return s.enableStopOnDisconnect()
+}
+
+func (s *SpheroDriver) enableStopOnDisconnect() (err error) {
+	return s.sendPacket(s.craftPacket([]uint8{0x00, 0x00, 0x00, 0x01}, 0x02, 0x37))
 }
 
 func (s *SpheroDriver) handleCollisionDetected(data []uint8) {
@@ -325,62 +1179,400 @@ func (s *SpheroDriver) handleCollisionDetected(data []uint8) {
 	if len(data) != 22 || data[4] != 17 {
 		return
 	}
+	s.mtx.Lock()
+	noCollisions := s.noCollisions
+	s.mtx.Unlock()
+	if noCollisions {
+		return
+	}
 	var collision CollisionPacket
 	buffer := bytes.NewBuffer(data[5:]) // skip header
 	binary.Read(buffer, binary.BigEndian, &collision)
 	s.Publish(Collision, collision)
+	s.handleBump()
 }
 
-func (s *SpheroDriver) handleDataStreaming(data []uint8) {
+func (s *SpheroDriver) handleBump() {
+	s.mtx.Lock()
+	behavior, speed, heading := s.bumpBehavior, s.rollSpeed, s.rollHeading
+	s.mtx.Unlock()
+
+	if speed == 0 {
+		return
+	}
+
+	switch behavior {
+	case BumpStop:
+		s.roll(0, heading)
+	case BumpReverse:
+		s.roll(speed, (heading+180)%360)
+	default:
+		return
+	}
+
+	gobot.After(s.bumpDuration, func() {
+		s.mtx.Lock()
+		resume := s.rollSpeed == speed && s.rollHeading == heading
+		s.mtx.Unlock()
+		if resume {
+			s.roll(speed, heading)
+		}
+	})
+}
+
+func (s *SpheroDriver) handleSelfLevelComplete(data []uint8) {
 	// ensure data is the right length:
-	if len(data) != 90 {
+	if len(data) != 7 || data[4] != 2 {
 		return
 	}
-	var dataPacket DataStreamingPacket
-	buffer := bytes.NewBuffer(data[5:]) // skip header
-	binary.Read(buffer, binary.BigEndian, &dataPacket)
-	s.Publish(SensorData, dataPacket)
+	s.Publish(SelfLevelComplete, data[5])
+}
+
+func (s *SpheroDriver) handleOrbBasic(data []uint8) {
+	// ensure data is the right length: header, message and checksum
+	if len(data) < 6 || len(data) != 5+(int(data[3])<<8|int(data[4])) {
+		return
+	}
+	s.Publish(OrbBasic, OrbBasicMessage{
+		Error:   data[2] == 0x09,
+		Message: string(data[5 : len(data)-1]),
+	})
+}
+
+// handleDataStreaming parses the frames of a data streaming packet, which
+// hold the fields selected by the masks of the data streaming configuration
+// last set, or of DefaultDataStreamingConfig. Fields not selected are 0.
+func (s *SpheroDriver) handleDataStreaming(data []uint8) {
+	s.mtx.Lock()
+	config := DefaultDataStreamingConfig()
+	if s.streaming != nil {
+		config = *s.streaming
+	}
+	s.mtx.Unlock()
+
+	fields := streamedFields(config)
+	frames := int(config.M)
+	if frames < 1 {
+		frames = 1
+	}
+	// ensure data is the right length: header, frames and checksum
+	if expected := 5 + 2*len(fields)*frames + 1; len(data) != expected {
+		s.Publish(Error, fmt.Errorf("Data streaming packet of %d bytes, expected %d bytes for the configured masks", len(data), expected))
+		return
+	}
+
+	body := data[5:]
+	for frame := 0; frame < frames; frame++ {
+		var dataPacket DataStreamingPacket
+		p := reflect.ValueOf(&dataPacket).Elem()
+		for i, field := range fields {
+			value := binary.BigEndian.Uint16(body[2*(frame*len(fields)+i):])
+			p.Field(field).SetInt(int64(int16(value)))
+		}
+		s.publishDataStreaming(dataPacket)
+	}
+}
+
+// streamedFields returns the indexes of the DataStreamingPacket fields
+// selected by the masks of config, in the order the Sphero streams them. The
+// fields follow the bits of Mask, then of Mask2, from the most significant.
+func streamedFields(config DataStreamingConfig) (fields []int) {
+	n := reflect.TypeOf(DataStreamingPacket{}).NumField()
+	for i := 0; i < n; i++ {
+		mask := config.Mask
+		if i >= 32 {
+			mask = config.Mask2
+		}
+		if mask&(1<<uint(31-i%32)) != 0 {
+			fields = append(fields, i)
+		}
+	}
+	return
+}
+
+func (s *SpheroDriver) publishDataStreaming(dataPacket DataStreamingPacket) {
+	s.mtx.Lock()
+	s.dataStreaming = &dataPacket
+	filtered := s.filterDataStreaming(dataPacket)
+	s.streamCount++
+	publish := s.streamDecimate <= 1 || s.streamCount%s.streamDecimate == 0
+	s.mtx.Unlock()
+
+	if publish {
+		s.Publish(SensorData, filtered)
+	}
 }
 
-func (s *SpheroDriver) getSyncResponse(packet *packet) []byte {
+// SetStreamFilter sets the number of streamed frames averaged for each field
+// of the published SensorData events. A window of 0 or 1 disables filtering.
+func (s *SpheroDriver) SetStreamFilter(window int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.streamWindow = window
+	s.streamHistory = nil
+}
+
+// SetStreamDecimation sets the SensorData events to be published only for
+// every nth streamed frame, after filtering. A value of 0 or 1 publishes
+// every frame.
+func (s *SpheroDriver) SetStreamDecimation(n int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.streamDecimate = n
+	s.streamCount = 0
+}
+
+// filterDataStreaming returns the moving average of the last streamed frames,
+// including p, over the stream filter window.
+func (s *SpheroDriver) filterDataStreaming(p DataStreamingPacket) DataStreamingPacket {
+	if s.streamWindow <= 1 {
+		return p
+	}
+
+	s.streamHistory = append(s.streamHistory, p)
+	if len(s.streamHistory) > s.streamWindow {
+		s.streamHistory = s.streamHistory[len(s.streamHistory)-s.streamWindow:]
+	}
+
+	var filtered DataStreamingPacket
+	f := reflect.ValueOf(&filtered).Elem()
+	for i := 0; i < f.NumField(); i++ {
+		var sum int64
+		for _, h := range s.streamHistory {
+			sum += reflect.ValueOf(h).Field(i).Int()
+		}
+		f.Field(i).SetInt(int64(math.Round(float64(sum) / float64(len(s.streamHistory)))))
+	}
+	return filtered
+}
+
+// Orientation returns the latest orientation quaternion streamed by the
+// Sphero, which requires the quaternion bits of DataStreamingConfig.Mask2.
+func (s *SpheroDriver) Orientation() (q0, q1, q2, q3 float64, err error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.dataStreaming == nil {
+		return 0, 0, 0, 0, ErrNoOrientation
+	}
+	d := s.dataStreaming
+	return float64(d.Quat0) / 10000, float64(d.Quat1) / 10000, float64(d.Quat2) / 10000, float64(d.Quat3) / 10000, nil
+}
+
+// OrientationEuler returns the roll, pitch and yaw angles in degrees derived
+// from the latest orientation quaternion streamed by the Sphero.
+func (s *SpheroDriver) OrientationEuler() (roll, pitch, yaw float64, err error) {
+	q0, q1, q2, q3, err := s.Orientation()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	roll, pitch, yaw = QuaternionToEuler(q0, q1, q2, q3)
+	return
+}
+
+// QuaternionToEuler converts the (q0, q1, q2, q3) orientation quaternion,
+// where q0 is the scalar part, to roll, pitch and yaw angles in degrees.
+func QuaternionToEuler(q0, q1, q2, q3 float64) (roll, pitch, yaw float64) {
+	roll = math.Atan2(2*(q0*q1+q2*q3), 1-2*(q1*q1+q2*q2))
+	pitch = math.Asin(math.Max(-1, math.Min(1, 2*(q0*q2-q3*q1))))
+	yaw = math.Atan2(2*(q0*q3+q1*q2), 1-2*(q2*q2+q3*q3))
+	return roll * 180 / math.Pi, pitch * 180 / math.Pi, yaw * 180 / math.Pi
+}
+
+func (s *SpheroDriver) popAsyncResponse() []uint8 {
+	s.responseMtx.Lock()
+	defer s.responseMtx.Unlock()
+	if len(s.asyncResponse) == 0 {
+		return nil
+	}
+	var evt []uint8
+	evt, s.asyncResponse = s.asyncResponse[len(s.asyncResponse)-1], s.asyncResponse[:len(s.asyncResponse)-1]
+	return evt
+}
+
+// SetSyncTimeout sets how long to wait for the Sphero to answer a synchronous
+// request, such as GetRGB or ReadLocator. Defaults to 50 milliseconds.
+func (s *SpheroDriver) SetSyncTimeout(timeout time.Duration) {
+	s.responseMtx.Lock()
+	defer s.responseMtx.Unlock()
+	s.syncTimeout = timeout
+}
+
+// SetSyncRetries sets how many times a read, such as GetRGB, is sent again
+// when the Sphero does not answer within the sync timeout. Defaults to 2.
+func (s *SpheroDriver) SetSyncRetries(retries int) {
+	s.responseMtx.Lock()
+	defer s.responseMtx.Unlock()
+	s.syncRetries = retries
+}
+
+// sendPacket queues the packet and, once the driver is started, waits until
+// it has been written to the Sphero. Before Start the packet is only queued.
+func (s *SpheroDriver) sendPacket(packet *packet) error {
+	s.mtx.Lock()
+	done := s.done
+	s.mtx.Unlock()
+
 	s.packetChannel <- packet
-	for i := 0; i < 500; i++ {
-		for key := range s.syncResponse {
-			if s.syncResponse[key][3] == packet.header[4] && len(s.syncResponse[key]) > 6 {
-				var response []byte
-				response, s.syncResponse = s.syncResponse[len(s.syncResponse)-1], s.syncResponse[:len(s.syncResponse)-1]
-				return response
-			}
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case err := <-packet.done:
+		return err
+	case <-done:
+		select {
+		case err := <-packet.done:
+			return err
+		default:
+			return ErrDriverHalted
 		}
-		time.Sleep(100 * time.Microsecond)
+	}
+}
+
+// Flush blocks until the packets queued before it are written to the Sphero,
+// and returns ErrFlushTimeout when they are not within timeout, which is
+// always the case when the driver is not started.
+func (s *SpheroDriver) Flush(timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	marker := &packet{done: make(chan error, 1)}
+	select {
+	case s.packetChannel <- marker:
+	case <-timer.C:
+		return ErrFlushTimeout
+	}
+	select {
+	case err := <-marker.done:
+		return err
+	case <-timer.C:
+		return ErrFlushTimeout
+	}
+}
+
+// started reports whether the goroutines started by Start are running.
+func (s *SpheroDriver) started() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.done != nil
+}
+
+// failQueuedPackets fails the packets which are still queued when the driver
+// is halted.
+func (s *SpheroDriver) failQueuedPackets() {
+	for {
+		select {
+		case packet := <-s.packetChannel:
+			packet.done <- ErrDriverHalted
+		default:
+			return
+		}
+	}
+}
+
+// getSyncResponse sends the packet and waits for the response with the
+// matching sequence number, or returns ErrSyncTimeout.
+func (s *SpheroDriver) getSyncResponse(packet *packet) ([]byte, error) {
+	seq := packet.header[4]
+	response := make(chan []uint8, 1)
+
+	s.responseMtx.Lock()
+	s.syncRequests[seq] = response
+	timeout := s.syncTimeout
+	s.responseMtx.Unlock()
+
+	s.packetChannel <- packet
+
+	var err error
+	deadline := time.After(timeout)
+	done := packet.done
+	for err == nil {
+		select {
+		case buf := <-response:
+			return buf, nil
+		case err = <-done:
+			done = nil
+		case <-deadline:
+			err = ErrSyncTimeout
+		}
+	}
+
+	s.responseMtx.Lock()
+	if s.syncRequests[seq] == response {
+		delete(s.syncRequests, seq)
+		s.staleSequences[seq] = time.Now()
+	}
+	s.responseMtx.Unlock()
+	return []byte{}, err
+}
+
+// deliverSyncResponse hands a response to the request waiting on its
+// sequence number. Responses nobody is waiting for are dropped.
+func (s *SpheroDriver) deliverSyncResponse(data []uint8) {
+	// header and checksum, simple responses carry no data
+	if len(data) < 6 {
+		return
 	}
 
-	return []byte{}
+	s.responseMtx.Lock()
+	defer s.responseMtx.Unlock()
+	if _, ok := s.staleSequences[data[3]]; ok {
+		// late response to a timed out request, its sequence number is free again
+		delete(s.staleSequences, data[3])
+		return
+	}
+	if response, ok := s.syncRequests[data[3]]; ok {
+		delete(s.syncRequests, data[3])
+		response <- data
+	}
 }
 
 func (s *SpheroDriver) craftPacket(body []uint8, did byte, cid byte) *packet {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 	packet := new(packet)
+	packet.done = make(chan error, 1)
 	packet.body = body
 	dlen := len(packet.body) + 1
+	s.skipStaleSequences()
 	packet.header = []uint8{0xFF, 0xFF, did, cid, s.seq, uint8(dlen)}
 	packet.checksum = s.calculateChecksum(packet)
+	s.seq++
 	return packet
 }
 
+// skipStaleSequences advances s.seq past the sequence numbers of timed out
+// synchronous requests, so that their late responses, when the sequence
+// number wraps around, can not be taken for the response to a new request.
+func (s *SpheroDriver) skipStaleSequences() {
+	s.responseMtx.Lock()
+	defer s.responseMtx.Unlock()
+	for i := 0; i < 256; i++ {
+		timedOut, ok := s.staleSequences[s.seq]
+		if !ok {
+			return
+		}
+		if time.Since(timedOut) > staleSequenceTimeout {
+			delete(s.staleSequences, s.seq)
+			return
+		}
+		s.seq++
+	}
+}
+
 func (s *SpheroDriver) write(packet *packet) (err error) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 	buf := append(packet.header, packet.body...)
 	buf = append(buf, packet.checksum)
-	length, err := s.adaptor().sp.Write(buf)
+	sp, _ := s.adaptor().connection()
+	length, err := sp.Write(buf)
 	if err != nil {
 		return err
 	} else if length != len(buf) {
 		return errors.New("Not enough bytes written")
 	}
-	s.seq++
 	return
 }
 
@@ -397,22 +1589,65 @@ func calculateChecksum(buf []byte) byte {
 	return uint8(^(calculatedChecksum % 256))
 }
 
-func (s *SpheroDriver) readHeader() []uint8 {
-	return s.readNextChunk(5)
+// readPacket reads the next packet from the Sphero. It returns nil when the
+// read fails, done is closed, or the packet is corrupt, in which case the
+// stream is resynced.
+func (s *SpheroDriver) readPacket(done <-chan struct{}) []uint8 {
+	header := s.readNextChunk(5, done)
+	if header == nil {
+		return nil
+	}
+	if header[0] != 0xFF || (header[1] != 0xFF && header[1] != 0xFE) {
+		s.resync(header)
+		return nil
+	}
+
+	// synchronous responses carry the sequence number before their one byte
+	// length, asynchronous ones a two bytes length
+	length := int(header[4])
+	if header[1] == 0xFE {
+		length |= int(header[3]) << 8
+	}
+	body := s.readNextChunk(length, done)
+	if body == nil {
+		return nil
+	}
+	data := append(header, body...)
+	checksum := data[len(data)-1]
+	if checksum != calculateChecksum(data[2:len(data)-1]) {
+		s.resync(data)
+		return nil
+	}
+	return data
 }
 
-func (s *SpheroDriver) readBody(length uint8) []uint8 {
-	return s.readNextChunk(int(length))
+// resync drops the bytes of a corrupt packet up to the next start of packet
+// marker, 0xFF 0xFF or 0xFF 0xFE, and keeps the rest to be read again.
+func (s *SpheroDriver) resync(data []uint8) {
+	for i := 1; i < len(data); i++ {
+		if data[i] == 0xFF && (i == len(data)-1 || data[i+1] == 0xFF || data[i+1] == 0xFE) {
+			s.readBuffer = append(append([]uint8{}, data[i:]...), s.readBuffer...)
+			return
+		}
+	}
 }
 
-func (s *SpheroDriver) readNextChunk(length int) []uint8 {
+func (s *SpheroDriver) readNextChunk(length int, done <-chan struct{}) []uint8 {
 	read := make([]uint8, length)
-	bytesRead := 0
+	bytesRead := copy(read, s.readBuffer)
+	s.readBuffer = s.readBuffer[bytesRead:]
 
 	for bytesRead < length {
+		select {
+		case <-done:
+			return nil
+		default:
+		}
 		time.Sleep(1 * time.Millisecond)
-		n, err := s.adaptor().sp.Read(read[bytesRead:])
+		sp, _ := s.adaptor().connection()
+		n, err := sp.Read(read[bytesRead:])
 		if err != nil {
+			s.connectionLost(err)
 			return nil
 		}
 		bytesRead += n
@@ -2,9 +2,19 @@ package sphero
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"gobot.io/x/gobot"
 	"gobot.io/x/gobot/gobottest"
@@ -72,8 +82,9 @@ func TestSpheroDriver(t *testing.T) {
 	ret = d.Command("Stop")(nil)
 	gobottest.Assert(t, ret, nil)
 
+	d.SetSyncTimeout(time.Millisecond)
 	ret = d.Command("GetRGB")(nil)
-	gobottest.Assert(t, ret.([]byte), []byte{})
+	gobottest.Assert(t, ret, ErrSyncTimeout)
 
 	ret = d.Command("ReadLocator")(nil)
 	gobottest.Assert(t, ret, []int16{})
@@ -87,12 +98,581 @@ func TestSpheroDriverStart(t *testing.T) {
 	gobottest.Assert(t, d.Start(), nil)
 }
 
+func TestSpheroDriverStartNoOutput(t *testing.T) {
+	stdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	d := initTestSpheroDriver()
+	d.adaptor().connected = false
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, d.SetDataStreaming(DefaultDataStreamingConfig()), nil)
+	gobottest.Assert(t, d.Halt(), nil)
+
+	w.Close()
+	output, _ := ioutil.ReadAll(r)
+	gobottest.Assert(t, string(output), "")
+}
+
+func TestSpheroDriverSyncResponse(t *testing.T) {
+	d := initTestSpheroDriver()
+	d.SetSyncTimeout(time.Second)
+
+	// answer every GetRGB with its own sequence number as the color
+	go func() {
+		for packet := range d.packetChannel {
+			seq := packet.header[4]
+			d.deliverSyncResponse([]uint8{0xFF, 0xFF, 0x00, seq, 0x04, seq, seq, seq, 0x00})
+		}
+	}()
+
+	done := make(chan bool)
+	for i := 0; i < 10; i++ {
+		go func() {
+			packet := d.craftPacket([]uint8{}, 0x02, 0x22)
+			buf, err := d.getSyncResponse(packet)
+			gobottest.Assert(t, err, nil)
+			gobottest.Assert(t, buf[5], packet.header[4])
+			done <- true
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+}
+
+func TestSpheroDriverSetRGBPersist(t *testing.T) {
+	d := initTestSpheroDriver()
+	gobottest.Assert(t, d.SetRGB(255, 0, 128), nil)
+	data := <-d.packetChannel
+	gobottest.Assert(t, data.header[2:4], []uint8{0x02, 0x20})
+	gobottest.Assert(t, data.body, []uint8{255, 0, 128, 0x01})
+
+	gobottest.Assert(t, d.SetRGBPersist(0, 255, 0, false), nil)
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.body, []uint8{0, 255, 0, 0x00})
+
+	ret := d.Command("SetRGBPersist")(
+		map[string]interface{}{"r": 1.0, "g": 2.0, "b": 3.0, "persist": true},
+	)
+	gobottest.Assert(t, ret, nil)
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.body, []uint8{1, 2, 3, 0x01})
+}
+
+func TestSpheroDriverGetRGB(t *testing.T) {
+	d := initTestSpheroDriver()
+	d.SetSyncTimeout(10 * time.Millisecond)
+
+	// the first request is lost, the retry is answered
+	go func() {
+		<-d.packetChannel
+		packet := <-d.packetChannel
+		gobottest.Assert(t, packet.header[2:4], []uint8{0x02, 0x22})
+		d.deliverSyncResponse([]uint8{0xFF, 0xFF, 0x00, packet.header[4], 0x04, 0x10, 0x20, 0x30, 0x00})
+	}()
+	r, g, b, err := d.GetRGB()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, []uint8{r, g, b}, []uint8{0x10, 0x20, 0x30})
+
+	go func() {
+		packet := <-d.packetChannel
+		d.deliverSyncResponse([]uint8{0xFF, 0xFF, 0x00, packet.header[4], 0x01, 0x00})
+	}()
+	_, _, _, err = d.GetRGB()
+	gobottest.Assert(t, err, ErrShortResponse)
+
+	// a read is sent once more per retry before timing out
+	d.SetSyncRetries(1)
+	go func() {
+		<-d.packetChannel
+		<-d.packetChannel
+	}()
+	r, g, b, err = d.GetRGB()
+	gobottest.Assert(t, err, ErrSyncTimeout)
+	gobottest.Assert(t, len(d.packetChannel), 0)
+
+	// a black LED is not mistaken for a failed read
+	ret := d.Command("GetRGB")
+	go func() {
+		packet := <-d.packetChannel
+		d.deliverSyncResponse([]uint8{0xFF, 0xFF, 0x00, packet.header[4], 0x04, 0x00, 0x00, 0x00, 0x00})
+	}()
+	gobottest.Assert(t, ret(nil), []uint8{0, 0, 0})
+}
+
+func TestSpheroDriverGetVersioning(t *testing.T) {
+	d := initTestSpheroDriver()
+	d.SetSyncTimeout(10 * time.Millisecond)
+
+	go func() {
+		packet := <-d.packetChannel
+		d.deliverSyncResponse([]uint8{0xFF, 0xFF, 0x00, packet.header[4], 0x09,
+			0x02, 0x01, 0x07, 0x03, 0x14, 0x41, 0x22, 0x33, 0x00})
+	}()
+	v, err := d.GetVersioning()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, v, Versioning{
+		RecordVersion:     0x02,
+		ModelNumber:       0x01,
+		HardwareVersion:   0x07,
+		MSAVersionMajor:   0x03,
+		MSAVersionMinor:   0x14,
+		BootloaderVersion: 0x41,
+		OrbBasicVersion:   0x22,
+		MacroVersion:      0x33,
+	})
+
+	// cached after the first query
+	cached, err := d.GetVersioning()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, cached, v)
+	gobottest.Assert(t, len(d.packetChannel), 0)
+
+	d = initTestSpheroDriver()
+	d.SetSyncTimeout(10 * time.Millisecond)
+	go func() {
+		packet := <-d.packetChannel
+		gobottest.Assert(t, packet.header[2:4], []uint8{0x00, 0x02})
+		d.deliverSyncResponse([]uint8{0xFF, 0xFF, 0x00, packet.header[4], 0x03, 0x02, 0x01, 0x00})
+	}()
+	v, err = d.GetVersioning()
+	gobottest.Assert(t, err, ErrShortResponse)
+	gobottest.Assert(t, v, Versioning{})
+
+	go func() { <-d.packetChannel }()
+	_, err = d.GetVersioning()
+	gobottest.Assert(t, err, ErrSyncTimeout)
+}
+
+func TestSpheroDriverSupports(t *testing.T) {
+	d := initTestSpheroDriver()
+	d.versioning = &Versioning{MSAVersionMajor: 1, MSAVersionMinor: 15}
+	supported, err := d.SupportsLocator()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, supported, true)
+	supported, _ = d.SupportsCollisionDetection()
+	gobottest.Assert(t, supported, true)
+	supported, _ = d.SupportsSelfLevel()
+	gobottest.Assert(t, supported, false)
+	supported, _ = d.SupportsOrbBasic()
+	gobottest.Assert(t, supported, false)
+
+	d.versioning = &Versioning{MSAVersionMajor: 3, MSAVersionMinor: 0, OrbBasicVersion: 0x22}
+	supported, _ = d.SupportsSelfLevel()
+	gobottest.Assert(t, supported, true)
+	supported, _ = d.SupportsOrbBasic()
+	gobottest.Assert(t, supported, true)
+
+	d.versioning = &Versioning{MSAVersionMajor: 0, MSAVersionMinor: 99}
+	supported, _ = d.SupportsLocator()
+	gobottest.Assert(t, supported, false)
+
+	d = initTestSpheroDriver()
+	d.SetSyncTimeout(10 * time.Millisecond)
+	go func() { <-d.packetChannel }()
+	supported, err = d.SupportsLocator()
+	gobottest.Assert(t, err, ErrSyncTimeout)
+	gobottest.Assert(t, supported, false)
+}
+
+func TestSpheroDriverConfigurationBlock(t *testing.T) {
+	d := initTestSpheroDriver()
+	d.SetSyncTimeout(10 * time.Millisecond)
+
+	go func() {
+		packet := <-d.packetChannel
+		gobottest.Assert(t, packet.header[2:4], []uint8{0x02, 0x40})
+		gobottest.Assert(t, packet.body, []uint8{ConfigBlockUser})
+		d.deliverSyncResponse([]uint8{0xFF, 0xFF, 0x00, packet.header[4], 0x04, 0x11, 0x22, 0x33, 0x00})
+	}()
+	block, err := d.GetConfigurationBlock(ConfigBlockUser)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, block, []byte{0x11, 0x22, 0x33})
+
+	go func() {
+		packet := <-d.packetChannel
+		d.deliverSyncResponse([]uint8{0xFF, 0xFF, 0x00, packet.header[4], 0x01, 0x00})
+	}()
+	_, err = d.GetConfigurationBlock(ConfigBlockDefault)
+	gobottest.Assert(t, err, ErrShortResponse)
+
+	_, err = d.GetConfigurationBlock(0x02)
+	gobottest.Assert(t, err, errors.New("Invalid configuration block 2, must be 0 or 1"))
+
+	go func() {
+		packet := <-d.packetChannel
+		gobottest.Assert(t, packet.header[2:4], []uint8{0x02, 0x43})
+		gobottest.Assert(t, packet.body, []uint8{0x11, 0x22, 0x33})
+		d.deliverSyncResponse([]uint8{0xFF, 0xFF, 0x00, packet.header[4], 0x01, 0x00})
+	}()
+	gobottest.Assert(t, d.SetConfigurationBlock(block), nil)
+
+	gobottest.Assert(t, d.SetConfigurationBlock([]byte{}), errors.New("Invalid configuration block length 0, must be between 1 and 254"))
+	gobottest.Refute(t, d.SetConfigurationBlock(make([]byte, ConfigBlockMaxSize+1)), nil)
+}
+
+func TestSpheroDriverSyncResponseWraparound(t *testing.T) {
+	d := initTestSpheroDriver()
+	d.SetSyncTimeout(5 * time.Millisecond)
+
+	// answer every GetRGB with the index of the request as the color, except
+	// every 50th request whose response is only sent when its sequence number
+	// is used again after wrapping around
+	go func() {
+		held := map[uint8][]uint8{}
+		i := 0
+		for packet := range d.packetChannel {
+			seq := packet.header[4]
+			if stale, ok := held[seq]; ok {
+				delete(held, seq)
+				d.deliverSyncResponse(stale)
+			}
+			response := []uint8{0xFF, 0xFF, 0x00, seq, 0x04, uint8(i >> 8), uint8(i), 0x00, 0x00}
+			if i%50 == 0 {
+				held[seq] = response
+			} else {
+				d.deliverSyncResponse(response)
+			}
+			i++
+		}
+	}()
+
+	for i := 0; i < 300; i++ {
+		buf, err := d.getSyncResponse(d.craftPacket([]uint8{}, 0x02, 0x22))
+		if i%50 == 0 {
+			gobottest.Assert(t, err, ErrSyncTimeout)
+			continue
+		}
+		gobottest.Assert(t, err, nil)
+		gobottest.Assert(t, int(buf[5])<<8|int(buf[6]), i)
+	}
+}
+
+func TestSpheroDriverSyncResponseTimeout(t *testing.T) {
+	d := initTestSpheroDriver()
+	d.SetSyncTimeout(10 * time.Millisecond)
+
+	buf, err := d.getSyncResponse(d.craftPacket([]uint8{}, 0x02, 0x22))
+	gobottest.Assert(t, err, ErrSyncTimeout)
+	gobottest.Assert(t, buf, []byte{})
+	gobottest.Assert(t, len(d.syncRequests), 0)
+}
+
+func TestSpheroDriverRollWithState(t *testing.T) {
+	d := initTestSpheroDriver()
+	gobottest.Assert(t, d.Roll(100, 270), nil)
+	data := <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x30))
+	gobottest.Assert(t, data.body, []uint8{100, 0x01, 0x0E, 0x01})
+
+	gobottest.Assert(t, d.RollWithState(0, 270, RollStateStop), nil)
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.body, []uint8{0, 0x01, 0x0E, 0x00})
+	gobottest.Assert(t, d.rollSpeed, uint8(0))
+	gobottest.Assert(t, d.rollHeading, uint16(270))
+
+	ret := d.Command("RollWithState")(
+		map[string]interface{}{"speed": 0.0, "heading": 0.0, "state": 2.0},
+	)
+	gobottest.Assert(t, ret, nil)
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.body, []uint8{0, 0x00, 0x00, 0x02})
+
+	gobottest.Assert(t, d.RollWithState(100, 90, 3), errors.New("Invalid roll state 3, must be between 0 and 2"))
+	gobottest.Assert(t, len(d.packetChannel), 0)
+}
+
+func TestSpheroDriverRollHeading(t *testing.T) {
+	d := initTestSpheroDriver()
+	for _, tt := range []struct {
+		heading  uint16
+		expected []uint8
+	}{
+		{359, []uint8{0x01, 0x67}},
+		{360, []uint8{0x00, 0x00}},
+		{720, []uint8{0x00, 0x00}},
+		{450, []uint8{0x00, 0x5A}},
+	} {
+		gobottest.Assert(t, d.Roll(100, tt.heading), nil)
+		data := <-d.packetChannel
+		gobottest.Assert(t, data.body[1:3], tt.expected)
+	}
+	gobottest.Assert(t, d.rollHeading, uint16(90))
+}
+
+func TestSpheroDriverRollMetric(t *testing.T) {
+	d := initTestSpheroDriver()
+	for _, tt := range []struct {
+		speed, heading float64
+		expected       []uint8
+	}{
+		{100, 90, []uint8{128, 0x00, 0x5A}},
+		{200, 359.6, []uint8{255, 0x00, 0x00}},
+		{500, 720, []uint8{255, 0x00, 0x00}},
+		{0, -90, []uint8{0, 0x01, 0x0E}},
+	} {
+		gobottest.Assert(t, d.RollMetric(tt.speed, tt.heading), nil)
+		data := <-d.packetChannel
+		gobottest.Assert(t, data.body[:3], tt.expected)
+	}
+
+	gobottest.Assert(t, d.SetMaxSpeed(100), nil)
+	gobottest.Assert(t, d.RollMetric(50, 0), nil)
+	data := <-d.packetChannel
+	gobottest.Assert(t, data.body[0], uint8(128))
+
+	gobottest.Assert(t, d.RollMetric(-1, 0), errors.New("Invalid speed -1 cm/s, must be finite and not negative"))
+	gobottest.Assert(t, d.RollMetric(math.NaN(), 0), errors.New("Invalid speed NaN cm/s, must be finite and not negative"))
+	gobottest.Assert(t, d.RollMetric(math.Inf(1), 0), errors.New("Invalid speed +Inf cm/s, must be finite and not negative"))
+	gobottest.Assert(t, d.RollMetric(50, math.NaN()), errors.New("Invalid heading NaN, must be finite"))
+	gobottest.Assert(t, d.RollMetric(50, math.Inf(-1)), errors.New("Invalid heading -Inf, must be finite"))
+	gobottest.Assert(t, len(d.packetChannel), 0)
+}
+
+func TestSpheroDriverSetMaxSpeed(t *testing.T) {
+	d := initTestSpheroDriver()
+	for _, speed := range []float64{0, -10, math.NaN(), math.Inf(1)} {
+		gobottest.Assert(t, d.SetMaxSpeed(speed), fmt.Errorf("Invalid top speed %v cm/s, must be finite and positive", speed))
+	}
+	gobottest.Assert(t, d.maxSpeed, DefaultMaxSpeed)
+}
+
+func TestSpheroDriverWriteError(t *testing.T) {
+	a, rwc := initTestSpheroAdaptor()
+	a.Connect()
+	d := NewSpheroDriver(a)
+	gobottest.Assert(t, d.Start(), nil)
+
+	gobottest.Assert(t, d.SetRGB(0, 0, 0), nil)
+	gobottest.Assert(t, d.Roll(100, 90), nil)
+
+	rwc.testAdaptorWrite = func(b []byte) (int, error) {
+		return 0, errors.New("write error")
+	}
+	gobottest.Assert(t, d.SetRGB(0, 0, 0), errors.New("write error"))
+	gobottest.Assert(t, d.Stop(), errors.New("write error"))
+	gobottest.Assert(t, d.Command("SetBackLED")(map[string]interface{}{"level": 100.0}), errors.New("write error"))
+
+	_, err := d.getSyncResponse(d.craftPacket([]uint8{}, 0x02, 0x22))
+	gobottest.Assert(t, err, errors.New("write error"))
+
+	rwc.testAdaptorWrite = func(b []byte) (int, error) {
+		return 1, nil
+	}
+	gobottest.Assert(t, d.SetHeading(0), errors.New("Not enough bytes written"))
+}
+
 func TestSpheroDriverHalt(t *testing.T) {
 	d := initTestSpheroDriver()
 	d.adaptor().connected = true
 	gobottest.Assert(t, d.Halt(), nil)
 }
 
+func TestSpheroDriverHaltStopsGoroutines(t *testing.T) {
+	d := initTestSpheroDriver()
+	d.adaptor().connected = false
+	before := runtime.NumGoroutine()
+
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, runtime.NumGoroutine() >= before+4, true)
+
+	gobottest.Assert(t, d.Halt(), nil)
+	gobottest.AssertMaxGoroutines(t, before)
+
+	// the driver can be started again after a halt
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestSpheroDriverStartContext(t *testing.T) {
+	d := initTestSpheroDriver()
+	d.adaptor().connected = false
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	gobottest.Assert(t, d.StartContext(ctx), nil)
+	gobottest.Assert(t, runtime.NumGoroutine() >= before+5, true)
+
+	cancel()
+	gobottest.AssertMaxGoroutines(t, before)
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestSpheroDriverKeepConnected(t *testing.T) {
+	a, rwc := initTestSpheroAdaptor()
+	a.KeepConnected = true
+	a.ReconnectInterval = time.Millisecond
+	connects := 0
+	a.connect = func(string) (io.ReadWriteCloser, error) {
+		if connects++; connects == 2 {
+			return nil, errors.New("connect error")
+		}
+		return rwc, nil
+	}
+	gobottest.Assert(t, a.Connect(), nil)
+	d := NewSpheroDriver(a)
+
+	events := make(chan interface{}, 2)
+	d.On(Reconnecting, func(data interface{}) { events <- data })
+	d.On(Reconnected, func(data interface{}) { events <- Reconnected })
+
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, d.SetDataStreaming(DefaultDataStreamingConfig()), nil)
+
+	var mtx sync.Mutex
+	var cids []uint8
+	fail := true
+	rwc.testAdaptorWrite = func(b []byte) (int, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if fail {
+			fail = false
+			return 0, errors.New("write error")
+		}
+		cids = append(cids, b[3])
+		return len(b), nil
+	}
+	gobottest.Assert(t, d.SetBackLED(255), errors.New("write error"))
+
+	for _, expected := range []interface{}{errors.New("write error"), Reconnected} {
+		select {
+		case data := <-events:
+			gobottest.Assert(t, data, expected)
+		case <-time.After(time.Second):
+			t.Fatalf("%v was not published", expected)
+		}
+	}
+	gobottest.Assert(t, connects, 3)
+	gobottest.Assert(t, d.Halt(), nil)
+
+	// locator, heading, collision detection, streaming and stop on disconnect
+	mtx.Lock()
+	defer mtx.Unlock()
+	gobottest.Assert(t, cids[:5], []uint8{0x13, 0x01, 0x12, 0x11, 0x37})
+}
+
+func TestSpheroDriverHaltBlockedRead(t *testing.T) {
+	a, rwc := initTestSpheroAdaptor()
+	a.Connect()
+	d := NewSpheroDriver(a)
+	closed := make(chan bool)
+	rwc.testAdaptorRead = func(p []byte) (int, error) {
+		<-closed
+		return 0, io.EOF
+	}
+	defer close(closed)
+
+	gobottest.Assert(t, d.Start(), nil)
+	halted := make(chan error)
+	go func() { halted <- d.Halt() }()
+	select {
+	case err := <-halted:
+		gobottest.Assert(t, err, nil)
+	case <-time.After(time.Second):
+		t.Errorf("Halt blocked on the pending read")
+	}
+}
+
+func TestSpheroDriverHaltDuringSend(t *testing.T) {
+	d := initTestSpheroDriver()
+	d.adaptor().connected = false
+	gobottest.Assert(t, d.Start(), nil)
+
+	errs := make(chan error)
+	for i := 0; i < 100; i++ {
+		go func() { errs <- d.SetRGB(1, 2, 3) }()
+	}
+	gobottest.Assert(t, d.Halt(), nil)
+
+	// packets still queued by Halt fail, the ones sent after it are queued
+	for i := 0; i < 100; i++ {
+		select {
+		case err := <-errs:
+			gobottest.Assert(t, err == nil || err == ErrDriverHalted, true)
+		case <-time.After(time.Second):
+			t.Fatalf("SetRGB blocked after Halt")
+		}
+	}
+}
+
+func TestSpheroDriverBufferSizes(t *testing.T) {
+	a, _ := initTestSpheroAdaptor()
+	d := NewSpheroDriver(a)
+	gobottest.Assert(t, cap(d.packetChannel), DefaultBufferSize)
+	gobottest.Assert(t, cap(d.responseChannel), DefaultBufferSize)
+
+	d = NewSpheroDriver(a, WithPacketBufferSize(4096), WithResponseBufferSize(16))
+	gobottest.Assert(t, cap(d.packetChannel), 4096)
+	gobottest.Assert(t, cap(d.responseChannel), 16)
+
+	d = NewSpheroDriver(a, WithPacketBufferSize(0), WithResponseBufferSize(-1))
+	gobottest.Assert(t, cap(d.packetChannel), 1)
+	gobottest.Assert(t, cap(d.responseChannel), 1)
+}
+
+func TestSpheroDriverFlush(t *testing.T) {
+	a, rwc := initTestSpheroAdaptor()
+	a.Connect()
+	d := NewSpheroDriver(a)
+	gobottest.Assert(t, d.Flush(time.Millisecond), ErrFlushTimeout)
+	<-d.packetChannel
+
+	gobottest.Assert(t, d.Start(), nil)
+
+	var mtx sync.Mutex
+	var cids []uint8
+	writing := make(chan bool)
+	blocked := make(chan bool)
+	rwc.testAdaptorWrite = func(b []byte) (int, error) {
+		if b[3] == 0x20 {
+			writing <- true
+			<-blocked
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		cids = append(cids, b[3])
+		return len(b), nil
+	}
+
+	go d.SetRGB(0, 0, 0)
+	<-writing
+	gobottest.Assert(t, d.Flush(10*time.Millisecond), ErrFlushTimeout)
+
+	close(blocked)
+	gobottest.Assert(t, d.Flush(time.Second), nil)
+	mtx.Lock()
+	gobottest.Assert(t, cids, []uint8{0x20})
+	mtx.Unlock()
+	gobottest.Assert(t, d.Halt(), nil)
+}
+
+func TestSpheroDriverHaltFlushes(t *testing.T) {
+	a, rwc := initTestSpheroAdaptor()
+	a.Connect()
+	d := NewSpheroDriver(a)
+	gobottest.Assert(t, d.Start(), nil)
+
+	var mtx sync.Mutex
+	var cids []uint8
+	rwc.testAdaptorWrite = func(b []byte) (int, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		cids = append(cids, b[3])
+		return len(b), nil
+	}
+
+	// queued without waiting for it to be written, like a last command sent
+	// from another goroutine
+	d.packetChannel <- d.craftPacket([]uint8{0, 0, 0, 1}, 0x02, 0x20)
+	gobottest.Assert(t, d.Halt(), nil)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	gobottest.Assert(t, cids, []uint8{0x20, 0x30})
+}
+
 func TestSpheroDriverSetDataStreaming(t *testing.T) {
 	d := initTestSpheroDriver()
 	d.SetDataStreaming(DefaultDataStreamingConfig())
@@ -123,6 +703,560 @@ func TestSpheroDriverSetDataStreaming(t *testing.T) {
 	gobottest.Assert(t, data.body, buf.Bytes())
 }
 
+func TestSpheroDriverConfigureCollisionDetectionCommand(t *testing.T) {
+	d := initTestSpheroDriver()
+	params := map[string]interface{}{
+		"Method": 1.0, "Xt": 128.0, "Yt": 129.0, "Xs": 130.0, "Ys": 131.0, "Dead": 10.0,
+	}
+	ret := d.Command("ConfigureCollisionDetection")(params)
+	gobottest.Assert(t, ret, nil)
+	data := <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x12))
+	gobottest.Assert(t, data.body, []uint8{1, 128, 129, 130, 131, 10})
+
+	delete(params, "Dead")
+	ret = d.Command("ConfigureCollisionDetection")(params)
+	gobottest.Assert(t, ret, errors.New("Missing collision detection parameter Dead"))
+	gobottest.Assert(t, len(d.packetChannel), 0)
+}
+
+func TestSpheroDriverDisableCollisionDetection(t *testing.T) {
+	collision := []uint8{0xFF, 0xFE, 0x07, 0x00, 17,
+		0x00, 0x01, 0x00, 0x02, 0x00, 0x03, 0x01, 0x00, 0x04, 0x00, 0x05, 0x64, 0x00, 0x00, 0x00, 0x01, 0x00}
+
+	d := initTestSpheroDriver()
+	collisions := make(chan CollisionPacket, 1)
+	d.On(Collision, func(data interface{}) {
+		collisions <- data.(CollisionPacket)
+	})
+
+	ret := d.Command("DisableCollisionDetection")(nil)
+	gobottest.Assert(t, ret, nil)
+	data := <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x12))
+	gobottest.Assert(t, data.body, []uint8{0, 0, 0, 0, 0, 0})
+
+	d.handleCollisionDetected(collision)
+	select {
+	case <-collisions:
+		t.Errorf("Collision published while detection is disabled")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Start does not enable the detection again
+	var mtx sync.Mutex
+	var cids []uint8
+	d.adaptor().sp.(*nullReadWriteCloser).testAdaptorWrite = func(b []byte) (int, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		cids = append(cids, b[3])
+		return len(b), nil
+	}
+	gobottest.Assert(t, d.Start(), nil)
+	gobottest.Assert(t, d.Halt(), nil)
+	mtx.Lock()
+	gobottest.Assert(t, cids, []uint8{0x37, 0x30})
+	mtx.Unlock()
+
+	d.ConfigureCollisionDetection(DefaultCollisionConfig())
+	<-d.packetChannel
+	d.handleCollisionDetected(collision)
+	select {
+	case <-collisions:
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("Collision not published once detection is configured")
+	}
+}
+
+func TestSpheroDriverSetRotationRate(t *testing.T) {
+	d := initTestSpheroDriver()
+	gobottest.Assert(t, d.SetRotationRate(20), nil)
+	data := <-d.packetChannel
+	gobottest.Assert(t, data.header[2], uint8(0x02))
+	gobottest.Assert(t, data.header[3], uint8(0x03))
+	gobottest.Assert(t, data.body, []uint8{20})
+	gobottest.Assert(t, d.RotationRate(), uint8(20))
+
+	ret := d.Command("SetRotationRate")(map[string]interface{}{"level": 255.0})
+	gobottest.Assert(t, ret, nil)
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.body, []uint8{255})
+
+	for _, params := range []map[string]interface{}{{"level": 256.0}, {"level": -1.0}, {}} {
+		ret = d.Command("SetRotationRate")(params)
+		gobottest.Assert(t, ret, errors.New("Rotation rate level must be between 0 and 255"))
+	}
+	gobottest.Assert(t, len(d.packetChannel), 0)
+	gobottest.Assert(t, d.RotationRate(), uint8(255))
+}
+
+func TestSpheroDriverCalibrationRoutine(t *testing.T) {
+	d := initTestSpheroDriver()
+	ret := d.Command("StartCalibration")(nil)
+	gobottest.Assert(t, ret, nil)
+	data := <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x21))
+	gobottest.Assert(t, data.body, []uint8{255})
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x30))
+	gobottest.Assert(t, data.body, []uint8{0, 0, 0, 0x02})
+
+	ret = d.Command("FinishCalibration")(map[string]interface{}{"heading": 90.0})
+	gobottest.Assert(t, ret, nil)
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x01))
+	gobottest.Assert(t, data.body, []uint8{0x00, 0x5A})
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x21))
+	gobottest.Assert(t, data.body, []uint8{0})
+
+	ret = d.Command("FinishCalibration")(nil)
+	gobottest.Assert(t, ret, errors.New("Missing calibration parameter heading"))
+	gobottest.Assert(t, len(d.packetChannel), 0)
+}
+
+func TestSpheroDriverFinishCalibrationError(t *testing.T) {
+	d := initTestSpheroDriver()
+	var mtx sync.Mutex
+	var cids []uint8
+	d.adaptor().sp.(*nullReadWriteCloser).testAdaptorWrite = func(b []byte) (int, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		cids = append(cids, b[3])
+		if b[3] == 0x01 {
+			return 0, errors.New("write error")
+		}
+		return len(b), nil
+	}
+	d.Start()
+	defer d.Halt()
+
+	// the back LED is turned off although setting the heading failed
+	gobottest.Assert(t, d.FinishCalibration(90), errors.New("write error"))
+	mtx.Lock()
+	gobottest.Assert(t, cids[len(cids)-2:], []uint8{0x01, 0x21})
+	mtx.Unlock()
+}
+
+func TestSpheroDriverSetInactivityTimeout(t *testing.T) {
+	d := initTestSpheroDriver()
+	gobottest.Assert(t, d.SetInactivityTimeout(3600), nil)
+
+	data := <-d.packetChannel
+	gobottest.Assert(t, data.header[2], uint8(0x02))
+	gobottest.Assert(t, data.header[3], uint8(0x25))
+	gobottest.Assert(t, data.body, []uint8{0x0E, 0x10})
+
+	// timeouts below the minimum are clamped to 60 seconds
+	ret := d.Command("SetInactivityTimeout")(
+		map[string]interface{}{"seconds": 10.0},
+	)
+	gobottest.Assert(t, ret, nil)
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.body, []uint8{0x00, 0x3C})
+}
+
+func TestSpheroDriverGetPermanentOptionFlags(t *testing.T) {
+	d := initTestSpheroDriver()
+	d.SetSyncTimeout(10 * time.Millisecond)
+
+	go func() {
+		packet := <-d.packetChannel
+		gobottest.Assert(t, packet.header[2:4], []uint8{0x02, 0x36})
+		d.deliverSyncResponse([]uint8{0xFF, 0xFF, 0x00, packet.header[4], 0x05, 0x00, 0x00, 0x01, 0x12, 0x00})
+	}()
+	flags, err := d.GetPermanentOptionFlags()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, flags, uint32(0x112))
+
+	go func() {
+		packet := <-d.packetChannel
+		d.deliverSyncResponse([]uint8{0xFF, 0xFF, 0x00, packet.header[4], 0x02, 0x01, 0x00})
+	}()
+	_, err = d.GetPermanentOptionFlags()
+	gobottest.Assert(t, err, ErrShortResponse)
+}
+
+// answerOptionFlags answers the permanent option flags request of d with
+// flags, after checking the motion timeout packet sent before it.
+func answerOptionFlags(t *testing.T, d *SpheroDriver, timeout []uint8, flags uint32) {
+	data := <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x34))
+	gobottest.Assert(t, data.body, timeout)
+
+	packet := <-d.packetChannel
+	gobottest.Assert(t, packet.header[3], uint8(0x36))
+	d.deliverSyncResponse([]uint8{0xFF, 0xFF, 0x00, packet.header[4], 0x05,
+		uint8(flags >> 24), uint8(flags >> 16), uint8(flags >> 8), uint8(flags), 0x00})
+}
+
+func TestSpheroDriverSetMotionTimeout(t *testing.T) {
+	d := initTestSpheroDriver()
+	d.SetSyncTimeout(100 * time.Millisecond)
+
+	// the flags already set on the Sphero are kept
+	go answerOptionFlags(t, d, []uint8{0x03, 0xE8}, 0x01)
+	gobottest.Assert(t, d.SetMotionTimeout(1000), nil)
+	data := <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x35))
+	gobottest.Assert(t, data.body, []uint8{0x00, 0x00, 0x00, 0x11})
+
+	// nothing is written when the flag is already set
+	go answerOptionFlags(t, d, []uint8{0x01, 0xF4}, 0x11)
+	ret := d.Command("SetMotionTimeout")(
+		map[string]interface{}{"timeout": 500.0},
+	)
+	gobottest.Assert(t, ret, nil)
+	gobottest.Assert(t, len(d.packetChannel), 0)
+
+	ret = d.Command("SetPermanentOptionFlags")(
+		map[string]interface{}{"flags": 1.0},
+	)
+	gobottest.Assert(t, ret, nil)
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.body, []uint8{0x00, 0x00, 0x00, 0x01})
+
+	// the flags are not overwritten when they can not be read
+	d.SetSyncTimeout(10 * time.Millisecond)
+	go func() {
+		<-d.packetChannel
+		<-d.packetChannel
+	}()
+	gobottest.Assert(t, d.SetMotionTimeout(1000), ErrSyncTimeout)
+	gobottest.Assert(t, len(d.packetChannel), 0)
+}
+
+func TestSpheroDriverBumpBehavior(t *testing.T) {
+	collision := []uint8{0xFF, 0xFE, 0x07, 0x00, 17,
+		0x00, 0x01, 0x00, 0x02, 0x00, 0x03, 0x01, 0x00, 0x04, 0x00, 0x05, 0x64, 0x00, 0x00, 0x00, 0x01, 0x00}
+
+	tests := []struct {
+		behavior BumpBehavior
+		bumps    [][]uint8
+	}{
+		{BumpNone, [][]uint8{}},
+		{BumpStop, [][]uint8{{0x00, 0x00, 0x5A, 0x01}, {0x64, 0x00, 0x5A, 0x01}}},
+		{BumpReverse, [][]uint8{{0x64, 0x01, 0x0E, 0x01}, {0x64, 0x00, 0x5A, 0x01}}},
+	}
+
+	for _, tt := range tests {
+		d := initTestSpheroDriver()
+		d.bumpDuration = 10 * time.Millisecond
+		d.SetBumpBehavior(tt.behavior)
+		d.Roll(100, 90)
+		<-d.packetChannel
+
+		d.handleCollisionDetected(collision)
+		for _, body := range tt.bumps {
+			select {
+			case data := <-d.packetChannel:
+				gobottest.Assert(t, data.header[3], uint8(0x30))
+				gobottest.Assert(t, data.body, body)
+			case <-time.After(100 * time.Millisecond):
+				t.Errorf("Expected follow-up roll for bump behavior %v", tt.behavior)
+			}
+		}
+		select {
+		case data := <-d.packetChannel:
+			t.Errorf("Unexpected packet %v for bump behavior %v", data.body, tt.behavior)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		// a stopped Sphero does not react to collisions
+		d.Stop()
+		<-d.packetChannel
+		d.handleCollisionDetected(collision)
+		select {
+		case data := <-d.packetChannel:
+			t.Errorf("Unexpected packet %v while stopped", data.body)
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+func TestSpheroDriverSelfLevel(t *testing.T) {
+	d := initTestSpheroDriver()
+	d.SelfLevel(0x01, 3, 15, 30)
+
+	data := <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x09))
+	gobottest.Assert(t, data.body, []uint8{0x01, 3, 15, 30})
+
+	ret := d.Command("SelfLevel")(
+		map[string]interface{}{"options": 0.0, "angleLimit": 0.0, "timeout": 0.0, "trueTime": 0.0},
+	)
+	gobottest.Assert(t, ret, nil)
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.body, []uint8{0, 0, 0, 0})
+
+	sem := make(chan bool)
+	d.Once(SelfLevelComplete, func(data interface{}) {
+		gobottest.Assert(t, data.(uint8), uint8(0x06))
+		sem <- true
+	})
+	d.handleSelfLevelComplete([]uint8{0xFF, 0xFE, 0x0B, 0x00, 0x02, 0x06, 0xEC})
+
+	select {
+	case <-sem:
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("SelfLevelComplete event was not published")
+	}
+}
+
+func TestSpheroDriverOrbBasic(t *testing.T) {
+	d := initTestSpheroDriver()
+	code := []byte(strings.Repeat("10 print \"hi\"\n", 30))
+	gobottest.Assert(t, d.AppendOrbBasicFragment(0x00, code), nil)
+
+	data := <-d.packetChannel
+	gobottest.Assert(t, data.header[2:4], []uint8{0x02, 0x58})
+	gobottest.Assert(t, len(data.body), OrbBasicFragmentSize+1)
+	gobottest.Assert(t, data.body[0], uint8(0x00))
+	gobottest.Assert(t, data.body[1:], code[:OrbBasicFragmentSize])
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.body[1:], code[OrbBasicFragmentSize:])
+	gobottest.Assert(t, len(d.packetChannel), 0)
+
+	d.ExecuteOrbBasic(0x01, 10)
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x5B))
+	gobottest.Assert(t, data.body, []uint8{0x01, 0x00, 0x0A})
+
+	ret := d.Command("AbortOrbBasic")(map[string]interface{}{})
+	gobottest.Assert(t, ret, nil)
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x5C))
+	gobottest.Assert(t, data.body, []uint8{})
+
+	sem := make(chan OrbBasicMessage)
+	d.On(OrbBasic, func(data interface{}) {
+		sem <- data.(OrbBasicMessage)
+	})
+	d.handleOrbBasic([]uint8{0xFF, 0xFE, 0x08, 0x00, 0x03, 'h', 'i', 0x00})
+	select {
+	case msg := <-sem:
+		gobottest.Assert(t, msg, OrbBasicMessage{Message: "hi"})
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("OrbBasic event was not published")
+	}
+	d.handleOrbBasic([]uint8{0xFF, 0xFE, 0x09, 0x00, 0x04, 'e', 'r', 'r', 0x00})
+	select {
+	case msg := <-sem:
+		gobottest.Assert(t, msg, OrbBasicMessage{Error: true, Message: "err"})
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("OrbBasic event was not published")
+	}
+}
+
+func TestSpheroDriverCalibration(t *testing.T) {
+	d := initTestSpheroDriver()
+	gobottest.Assert(t, d.SaveCalibration(), Calibration{
+		Locator:   DefaultLocatorConfig(),
+		Collision: DefaultCollisionConfig(),
+	})
+
+	lconfig := LocatorConfig{Flags: 1, X: 10, Y: -20, YawTare: 30}
+	cconfig := CollisionConfig{Method: 1, Xt: 0x40, Yt: 0x40, Xs: 0x50, Ys: 0x50, Dead: 0x10}
+	d.ConfigureLocator(lconfig)
+	d.SetHeading(45)
+	d.ConfigureCollisionDetection(cconfig)
+	for i := 0; i < 3; i++ {
+		<-d.packetChannel
+	}
+
+	c := d.SaveCalibration()
+	gobottest.Assert(t, c, Calibration{Locator: lconfig, Heading: 45, Collision: cconfig})
+
+	d = initTestSpheroDriver()
+	d.RestoreCalibration(c)
+	gobottest.Assert(t, d.SaveCalibration(), c)
+
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, lconfig)
+	data := <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x13))
+	gobottest.Assert(t, data.body, buf.Bytes())
+
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x01))
+	gobottest.Assert(t, data.body, []uint8{0x00, 45})
+
+	data = <-d.packetChannel
+	gobottest.Assert(t, data.header[3], uint8(0x12))
+	gobottest.Assert(t, data.body, []uint8{1, 0x40, 0x40, 0x50, 0x50, 0x10})
+}
+
+func dataStreamingFrame(p DataStreamingPacket) []uint8 {
+	buf := new(bytes.Buffer)
+	buf.Write([]uint8{0xFF, 0xFE, 0x03, 0x00, 89})
+	binary.Write(buf, binary.BigEndian, p)
+	buf.WriteByte(0x00)
+	return buf.Bytes()
+}
+
+func TestSpheroDriverOrientation(t *testing.T) {
+	d := initTestSpheroDriver()
+	_, _, _, _, err := d.Orientation()
+	gobottest.Assert(t, err, ErrNoOrientation)
+	_, _, _, err = d.OrientationEuler()
+	gobottest.Assert(t, err, ErrNoOrientation)
+
+	// rotated 90 degrees around the z axis
+	d.handleDataStreaming(dataStreamingFrame(DataStreamingPacket{Quat0: 7071, Quat3: 7071}))
+
+	q0, q1, q2, q3, err := d.Orientation()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, q0, 0.7071)
+	gobottest.Assert(t, q1, 0.0)
+	gobottest.Assert(t, q2, 0.0)
+	gobottest.Assert(t, q3, 0.7071)
+
+	roll, pitch, yaw, err := d.OrientationEuler()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, math.Abs(roll) < 0.01, true)
+	gobottest.Assert(t, math.Abs(pitch) < 0.01, true)
+	gobottest.Assert(t, math.Abs(yaw-90) < 0.01, true)
+
+	// rotated -30 degrees around the x axis
+	d.handleDataStreaming(dataStreamingFrame(DataStreamingPacket{Quat0: 9659, Quat1: -2588}))
+	roll, pitch, yaw, err = d.OrientationEuler()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, math.Abs(roll+30) < 0.01, true)
+	gobottest.Assert(t, math.Abs(pitch) < 0.01, true)
+	gobottest.Assert(t, math.Abs(yaw) < 0.01, true)
+}
+
+func TestVersioningMSAVersionAtLeast(t *testing.T) {
+	v := Versioning{MSAVersionMajor: 1, MSAVersionMinor: 13}
+	gobottest.Assert(t, v.MSAVersionAtLeast(1, 13), true)
+	gobottest.Assert(t, v.MSAVersionAtLeast(1, 12), true)
+	gobottest.Assert(t, v.MSAVersionAtLeast(0, 20), true)
+	gobottest.Assert(t, v.MSAVersionAtLeast(1, 14), false)
+	gobottest.Assert(t, v.MSAVersionAtLeast(2, 0), false)
+}
+
+func TestSensorMask(t *testing.T) {
+	tests := []struct {
+		name        string
+		mask        SensorMask
+		bits, bits2 uint32
+	}{
+		{"AccelRaw", SensorMask{}.WithAccelRaw(), 0xE0000000, 0},
+		{"GyroRaw", SensorMask{}.WithGyroRaw(), 0x1C000000, 0},
+		{"MotorBackEMFRaw", SensorMask{}.WithMotorBackEMFRaw(), 0x00600000, 0},
+		{"MotorPWMRaw", SensorMask{}.WithMotorPWMRaw(), 0x00180000, 0},
+		{"IMUAngles", SensorMask{}.WithIMUAngles(), 0x00070000, 0},
+		{"Accel", SensorMask{}.WithAccel(), 0x0000E000, 0},
+		{"Gyro", SensorMask{}.WithGyro(), 0x00001C00, 0},
+		{"MotorBackEMF", SensorMask{}.WithMotorBackEMF(), 0x00000060, 0},
+		{"Quaternion", SensorMask{}.WithQuaternion(), 0, 0xF0000000},
+		{"Locator", SensorMask{}.WithLocator(), 0, 0x0D800000},
+		{"AccelOne", SensorMask{}.WithAccelOne(), 0, 0x02000000},
+		{"Combined", SensorMask{}.WithGyro().WithIMUAngles().WithLocator(), 0x00071C00, 0x0D800000},
+	}
+	for _, tt := range tests {
+		mask, mask2 := tt.mask.Masks()
+		if mask != tt.bits || mask2 != tt.bits2 {
+			t.Errorf("%s: got masks %#08x %#08x, expected %#08x %#08x", tt.name, mask, mask2, tt.bits, tt.bits2)
+		}
+	}
+}
+
+func TestSpheroDriverSetDataStreamingSensors(t *testing.T) {
+	d := initTestSpheroDriver()
+	gobottest.Assert(t, d.SetDataStreamingSensors(40, 2, SensorMask{}.WithGyroRaw().WithLocator()), nil)
+
+	data := <-d.packetChannel
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, DataStreamingConfig{N: 10, M: 2, Mask: 0x1C000000, Mask2: 0x0D800000})
+	gobottest.Assert(t, data.body, buf.Bytes())
+
+	gobottest.Assert(t, d.SetDataStreamingSensors(0, 1, SensorMask{}.WithGyro()),
+		errors.New("Invalid data streaming frequency 0Hz, must be between 1 and 400Hz"))
+	gobottest.Assert(t, d.SetDataStreamingSensors(401, 1, SensorMask{}.WithGyro()),
+		errors.New("Invalid data streaming frequency 401Hz, must be between 1 and 400Hz"))
+	gobottest.Assert(t, d.SetDataStreamingSensors(10, 0, SensorMask{}.WithGyro()),
+		errors.New("Invalid data streaming frames, must be at least 1"))
+}
+
+func TestSpheroDriverDataStreamingMask(t *testing.T) {
+	d := initTestSpheroDriver()
+	events := make(chan interface{}, 3)
+	d.On(SensorData, func(data interface{}) { events <- data })
+	d.On(Error, func(data interface{}) { events <- data })
+
+	// raw gyro and odometer, two frames per packet
+	gobottest.Assert(t, d.SetDataStreaming(DataStreamingConfig{N: 10, M: 2, Mask: 0x1C000000, Mask2: 0x0C000000}), nil)
+	d.handleDataStreaming([]uint8{0xFF, 0xFE, 0x03, 0x00, 21,
+		0x00, 0x01, 0x00, 0x02, 0xFF, 0xFD, 0x00, 0x04, 0x00, 0x05,
+		0x00, 0x06, 0x00, 0x07, 0x00, 0x08, 0x00, 0x09, 0x00, 0x0A,
+		0x00})
+
+	for _, expected := range []DataStreamingPacket{
+		{RawGyroX: 1, RawGyroY: 2, RawGyroZ: -3, OdomX: 4, OdomY: 5},
+		{RawGyroX: 6, RawGyroY: 7, RawGyroZ: 8, OdomX: 9, OdomY: 10},
+	} {
+		select {
+		case data := <-events:
+			gobottest.Assert(t, data, expected)
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("SensorData event was not published")
+		}
+	}
+
+	// a frame of the default configuration does not match the masks
+	d.handleDataStreaming(dataStreamingFrame(DataStreamingPacket{}))
+	select {
+	case data := <-events:
+		gobottest.Assert(t, data, errors.New("Data streaming packet of 88 bytes, expected 26 bytes for the configured masks"))
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("Error event was not published")
+	}
+}
+
+func TestSpheroDriverStreamFilter(t *testing.T) {
+	tests := []struct {
+		window, decimation int
+		expected           []int16
+	}{
+		{0, 0, []int16{10, 20, 30, 50, -30}},
+		{2, 0, []int16{10, 15, 25, 40, 10}},
+		{3, 0, []int16{10, 15, 20, 33, 17}},
+		{0, 2, []int16{20, 50}},
+		{2, 2, []int16{15, 40}},
+	}
+
+	for _, tt := range tests {
+		d := initTestSpheroDriver()
+		d.SetStreamFilter(tt.window)
+		d.SetStreamDecimation(tt.decimation)
+
+		events := make(chan DataStreamingPacket, 10)
+		d.On(SensorData, func(data interface{}) {
+			events <- data.(DataStreamingPacket)
+		})
+
+		for _, v := range []int16{10, 20, 30, 50, -30} {
+			d.handleDataStreaming(dataStreamingFrame(DataStreamingPacket{RawAccX: v, FiltYaw: -v}))
+		}
+
+		for _, v := range tt.expected {
+			select {
+			case p := <-events:
+				gobottest.Assert(t, p.RawAccX, v)
+				gobottest.Assert(t, p.FiltYaw, -v)
+			case <-time.After(100 * time.Millisecond):
+				t.Errorf("SensorData event was not published")
+			}
+		}
+		select {
+		case p := <-events:
+			t.Errorf("Unexpected SensorData event %v", p.RawAccX)
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
 func TestConfigureLocator(t *testing.T) {
 	d := initTestSpheroDriver()
 	d.ConfigureLocator(DefaultLocatorConfig())
@@ -151,6 +1285,96 @@ func TestConfigureLocator(t *testing.T) {
 	gobottest.Assert(t, data.body, buf.Bytes())
 }
 
+func TestSpheroDriverPackets(t *testing.T) {
+	tests := []struct {
+		send     func(d *SpheroDriver) error
+		expected []byte
+	}{
+		{
+			func(d *SpheroDriver) error { return d.Roll(100, 90) },
+			[]byte{0xFF, 0xFF, 0x02, 0x30, 0x00, 0x05, 0x64, 0x00, 0x5A, 0x01, 0x09},
+		},
+		{
+			func(d *SpheroDriver) error { return d.Stop() },
+			[]byte{0xFF, 0xFF, 0x02, 0x30, 0x00, 0x05, 0x00, 0x00, 0x00, 0x01, 0xC7},
+		},
+		{
+			func(d *SpheroDriver) error { return d.SetRGB(255, 0, 128) },
+			[]byte{0xFF, 0xFF, 0x02, 0x20, 0x00, 0x05, 0xFF, 0x00, 0x80, 0x01, 0x58},
+		},
+		{
+			func(d *SpheroDriver) error { return d.SetHeading(300) },
+			[]byte{0xFF, 0xFF, 0x02, 0x01, 0x00, 0x03, 0x01, 0x2C, 0xCC},
+		},
+		{
+			func(d *SpheroDriver) error { return d.SetBackLED(255) },
+			[]byte{0xFF, 0xFF, 0x02, 0x21, 0x00, 0x02, 0xFF, 0xDB},
+		},
+		{
+			func(d *SpheroDriver) error { return d.SetStabilization(false) },
+			[]byte{0xFF, 0xFF, 0x02, 0x02, 0x00, 0x02, 0x00, 0xF9},
+		},
+	}
+
+	for _, tt := range tests {
+		a, rwc := initTestSpheroAdaptor()
+		var written []byte
+		rwc.testAdaptorWrite = func(b []byte) (int, error) {
+			written = append(written, b...)
+			return len(b), nil
+		}
+		a.Connect()
+		d := NewSpheroDriver(a)
+
+		gobottest.Assert(t, tt.send(d), nil)
+		gobottest.Assert(t, d.write(<-d.packetChannel), nil)
+		gobottest.Assert(t, written, tt.expected)
+	}
+}
+
+func TestSpheroDriverResync(t *testing.T) {
+	rgb := []uint8{0xFF, 0xFF, 0x00, 0x02, 0x04, 0x0A, 0x14, 0x1E, 0x00}
+	rgb[8] = calculateChecksum(rgb[2:8])
+
+	tests := map[string][]uint8{
+		// the corrupt length swallows the start of the next packet
+		"bad length":   append([]uint8{0xFF, 0xFF, 0x00, 0x01, 0x08, 0xFE}, rgb...),
+		"bad checksum": append([]uint8{0xFF, 0xFF, 0x00, 0x01, 0x01, 0x00}, rgb...),
+		"garbage":      append([]uint8{0x12, 0x34, 0xFF}, rgb...),
+	}
+	for name, stream := range tests {
+		t.Run(name, func(t *testing.T) {
+			a, rwc := initTestSpheroAdaptor()
+			a.Connect()
+			d := NewSpheroDriver(a)
+			buf := bytes.NewBuffer(stream)
+			rwc.testAdaptorRead = buf.Read
+
+			var data []uint8
+			for i := 0; i < 5 && data == nil; i++ {
+				data = d.readPacket(nil)
+			}
+			gobottest.Assert(t, data, rgb)
+		})
+	}
+}
+
+func TestSpheroDriverReadLongAsyncPacket(t *testing.T) {
+	// data streaming packet of 300 bytes of data and the checksum
+	packet := append([]uint8{0xFF, 0xFE, 0x03, 0x01, 0x2D}, make([]uint8, 301)...)
+	for i := 5; i < len(packet)-1; i++ {
+		packet[i] = uint8(i)
+	}
+	packet[len(packet)-1] = calculateChecksum(packet[2 : len(packet)-1])
+
+	a, rwc := initTestSpheroAdaptor()
+	a.Connect()
+	d := NewSpheroDriver(a)
+	rwc.testAdaptorRead = bytes.NewBuffer(packet).Read
+
+	gobottest.Assert(t, d.readPacket(nil), packet)
+}
+
 func TestCalculateChecksum(t *testing.T) {
 	tests := []struct {
 		data     []byte
@@ -59,6 +59,55 @@ type CollisionConfig struct {
 	Dead uint8
 }
 
+// Versioning holds the model and version numbers reported by a Sphero
+type Versioning struct {
+	// Version of this record, incremented when fields are added
+	RecordVersion uint8
+	// Model number, 0x01 for the Sphero 2.0
+	ModelNumber uint8
+	// Hardware version
+	HardwareVersion uint8
+	// Main Sphero application firmware version
+	MSAVersionMajor uint8
+	// Main Sphero application firmware revision
+	MSAVersionMinor uint8
+	// Bootloader version, as a packed BCD nibble pair
+	BootloaderVersion uint8
+	// orbBasic interpreter version, as a packed BCD nibble pair
+	OrbBasicVersion uint8
+	// Macro executive version, as a packed BCD nibble pair
+	MacroVersion uint8
+}
+
+// MSAVersionAtLeast reports whether the main Sphero application firmware is
+// at least version major.minor
+func (v Versioning) MSAVersionAtLeast(major, minor uint8) bool {
+	if v.MSAVersionMajor != major {
+		return v.MSAVersionMajor > major
+	}
+	return v.MSAVersionMinor >= minor
+}
+
+// OrbBasicMessage is published with the OrbBasic event when a running
+// orbBasic program prints a message or stops with an error
+type OrbBasicMessage struct {
+	// Error is true when the message is an orbBasic error
+	Error bool
+	// Message printed by the program, or the error description
+	Message string
+}
+
+// Calibration holds the coordinate frame configuration of a Sphero, so it can
+// be restored with SpheroDriver.RestoreCalibration
+type Calibration struct {
+	// Locator configuration, including the X, Y and yaw tare offsets
+	Locator LocatorConfig
+	// Heading set as the zero heading
+	Heading uint16
+	// Collision detection configuration
+	Collision CollisionConfig
+}
+
 // CollisionPacket represents the response from a Collision event
 type CollisionPacket struct {
 	// Normalized impact components (direction of the collision event):
@@ -99,6 +148,88 @@ type DataStreamingConfig struct {
 	Mask2 uint32
 }
 
+// SensorMask selects the sensors streamed with
+// SpheroDriver.SetDataStreamingSensors, such as
+// SensorMask{}.WithGyro().WithLocator(), so that the bits of the data
+// streaming masks need not be looked up.
+type SensorMask struct {
+	mask  uint32
+	mask2 uint32
+}
+
+// Masks returns the Mask and Mask2 of a DataStreamingConfig streaming the
+// selected sensors.
+func (m SensorMask) Masks() (mask, mask2 uint32) {
+	return m.mask, m.mask2
+}
+
+// WithAccelRaw selects the raw accelerometer axes X, Y and Z
+func (m SensorMask) WithAccelRaw() SensorMask {
+	m.mask |= 0xE0000000
+	return m
+}
+
+// WithGyroRaw selects the raw gyro axes X, Y and Z
+func (m SensorMask) WithGyroRaw() SensorMask {
+	m.mask |= 0x1C000000
+	return m
+}
+
+// WithMotorBackEMFRaw selects the raw right and left motor back EMF
+func (m SensorMask) WithMotorBackEMFRaw() SensorMask {
+	m.mask |= 0x00600000
+	return m
+}
+
+// WithMotorPWMRaw selects the raw left and right motor PWM
+func (m SensorMask) WithMotorPWMRaw() SensorMask {
+	m.mask |= 0x00180000
+	return m
+}
+
+// WithIMUAngles selects the filtered IMU pitch, roll and yaw angles
+func (m SensorMask) WithIMUAngles() SensorMask {
+	m.mask |= 0x00070000
+	return m
+}
+
+// WithAccel selects the filtered accelerometer axes X, Y and Z
+func (m SensorMask) WithAccel() SensorMask {
+	m.mask |= 0x0000E000
+	return m
+}
+
+// WithGyro selects the filtered gyro axes X, Y and Z
+func (m SensorMask) WithGyro() SensorMask {
+	m.mask |= 0x00001C00
+	return m
+}
+
+// WithMotorBackEMF selects the filtered right and left motor back EMF
+func (m SensorMask) WithMotorBackEMF() SensorMask {
+	m.mask |= 0x00000060
+	return m
+}
+
+// WithQuaternion selects the orientation quaternion, required by
+// SpheroDriver.Orientation
+func (m SensorMask) WithQuaternion() SensorMask {
+	m.mask2 |= 0xF0000000
+	return m
+}
+
+// WithLocator selects the odometer X and Y and the velocity X and Y
+func (m SensorMask) WithLocator() SensorMask {
+	m.mask2 |= 0x0D800000
+	return m
+}
+
+// WithAccelOne selects the magnitude of the acceleration
+func (m SensorMask) WithAccelOne() SensorMask {
+	m.mask2 |= 0x02000000
+	return m
+}
+
 // DataStreamingPacket represents the response from a Data Streaming event
 type DataStreamingPacket struct {
 	// 8000 0000h	accelerometer axis X, raw	-2048 to 2047	4mG
@@ -1,10 +1,12 @@
 package gobot
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"sync/atomic"
 
 	multierror "github.com/hashicorp/go-multierror"
@@ -150,13 +152,20 @@ func (r *Robot) Start(args ...interface{}) (err error) {
 	if len(args) > 0 && args[0] != nil {
 		r.AutoRun = args[0].(bool)
 	}
+	return r.StartContext(context.Background())
+}
+
+// StartContext starts a Robot like Start, with its Devices started with ctx,
+// see StartContext. When auto-running, the Robot is stopped once ctx is done
+// as well as on interrupt.
+func (r *Robot) StartContext(ctx context.Context) (err error) {
 	log.Println("Starting Robot", r.Name, "...")
 	if cerr := r.Connections().Start(); cerr != nil {
 		err = multierror.Append(err, cerr)
 		log.Println(err)
 		return
 	}
-	if derr := r.Devices().Start(); derr != nil {
+	if derr := r.Devices().StartContext(ctx); derr != nil {
 		err = multierror.Append(err, derr)
 		log.Println(err)
 		return
@@ -176,8 +185,11 @@ func (r *Robot) Start(args ...interface{}) (err error) {
 		c := make(chan os.Signal, 1)
 		r.trap(c)
 
-		// waiting for interrupt coming on the channel
-		<-c
+		// waiting for interrupt coming on the channel, or for ctx to be done
+		select {
+		case <-c:
+		case <-ctx.Done():
+		}
 
 		// Stop calls the Stop method on itself, if we are "auto-running".
 		r.Stop()
@@ -204,6 +216,58 @@ func (r *Robot) Stop() error {
 	return result
 }
 
+// exit ends the program once the handler installed by OnShutdown has stopped
+// the Robot
+var exit = os.Exit
+
+// OnShutdown installs an interrupt handler which stops the Robot, halting its
+// Devices and then finalizing its Connections, and then ends the program,
+// instead of letting the interrupt end it while drivers are still writing.
+// The Robot is only stopped once, even when the interrupt repeats. Calling
+// the returned cleanup func removes the handler.
+//
+// It is meant for Robots started without AutoRun, which stop on interrupt
+// by themselves.
+func OnShutdown(r *Robot) (cleanup func()) {
+	cleanup, _ = onShutdown(r)
+	return
+}
+
+// onShutdown installs the handler of OnShutdown, and also returns a channel
+// closed once the handler has returned.
+func onShutdown(r *Robot) (cleanup func(), returned <-chan struct{}) {
+	c := make(chan os.Signal, 1)
+	r.trap(c)
+
+	done := make(chan struct{})
+	handled := make(chan struct{})
+	go func() {
+		defer close(handled)
+		select {
+		case <-c:
+			// an interrupt may be waiting when cleanup is called
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if err := r.Stop(); err != nil {
+				log.Println(err)
+			}
+			exit(0)
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}, handled
+}
+
 // Running returns if the Robot is currently started or not
 func (r *Robot) Running() bool {
 	return r.running.Load().(bool)
@@ -1,6 +1,8 @@
 package gobot
 
 import (
+	"context"
+	"os"
 	"testing"
 	"time"
 
@@ -44,6 +46,64 @@ func TestRobotStart(t *testing.T) {
 	gobottest.Assert(t, r.Running(), false)
 }
 
+func TestRobotOnShutdown(t *testing.T) {
+	exited := make(chan int, 2)
+	exit = func(code int) { exited <- code }
+	defer func() { exit = os.Exit }()
+
+	r := newTestRobot("Robot99")
+	r.trap = func(c chan os.Signal) {}
+	gobottest.Assert(t, r.Start(false), nil)
+
+	var trapped chan os.Signal
+	r.trap = func(c chan os.Signal) { trapped = c }
+	cleanup := OnShutdown(r)
+	defer cleanup()
+
+	trapped <- os.Interrupt
+	select {
+	case code := <-exited:
+		gobottest.Assert(t, code, 0)
+	case <-time.After(time.Second):
+		t.Fatalf("Robot was not stopped on interrupt")
+	}
+	gobottest.Assert(t, r.Running(), false)
+
+	// a repeated interrupt does not stop the Robot again
+	trapped <- os.Interrupt
+	select {
+	case <-exited:
+		t.Errorf("Robot was stopped twice")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestRobotOnShutdownCleanup(t *testing.T) {
+	exit = func(code int) { t.Errorf("exit called after cleanup") }
+	defer func() { exit = os.Exit }()
+
+	r := newTestRobot("Robot99")
+	r.trap = func(c chan os.Signal) {}
+	gobottest.Assert(t, r.Start(false), nil)
+
+	var trapped chan os.Signal
+	r.trap = func(c chan os.Signal) { trapped = c }
+	cleanup, returned := onShutdown(r)
+
+	cleanup()
+	cleanup()
+	// an interrupt coming along cleanup is ignored
+	trapped <- os.Interrupt
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatalf("handler did not return after cleanup")
+	}
+	gobottest.Assert(t, r.Running(), true)
+	gobottest.Assert(t, r.Stop(), nil)
+}
+
 func TestRobotStartAutoRun(t *testing.T) {
 	adaptor1 := newTestAdaptor("Connection1", "/dev/null")
 	driver1 := newTestDriver(adaptor1, "Device1", "0")
@@ -65,3 +125,40 @@ func TestRobotStartAutoRun(t *testing.T) {
 	gobottest.Assert(t, r.Stop(), nil)
 	gobottest.Assert(t, r.Running(), false)
 }
+
+type testContextDriver struct {
+	*testDriver
+	ctx context.Context
+}
+
+func (t *testContextDriver) StartContext(ctx context.Context) error {
+	t.ctx = ctx
+	return nil
+}
+
+func TestRobotStartContext(t *testing.T) {
+	adaptor1 := newTestAdaptor("Connection1", "/dev/null")
+	driver1 := &testContextDriver{testDriver: newTestDriver(adaptor1, "Device1", "0")}
+	r := NewRobot("context",
+		[]Connection{adaptor1},
+		[]Device{driver1},
+	)
+	r.trap = func(c chan os.Signal) {}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan error)
+	go func() { started <- r.StartContext(ctx) }()
+
+	time.Sleep(10 * time.Millisecond)
+	gobottest.Assert(t, r.Running(), true)
+	gobottest.Assert(t, driver1.ctx, ctx)
+
+	cancel()
+	select {
+	case err := <-started:
+		gobottest.Assert(t, err, nil)
+	case <-time.After(time.Second):
+		t.Errorf("Robot was not stopped when the context was done")
+	}
+	gobottest.Assert(t, r.Running(), false)
+}
@@ -5,21 +5,50 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"sync"
 	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
 )
 
+// Ticker is returned by Every. Calling Stop stops f from being triggered
+// and releases the goroutine running it.
+type Ticker struct {
+	*time.Ticker
+	done chan bool
+	once sync.Once
+}
+
+// Stop stops the Ticker. It is safe to call Stop more than once.
+func (t *Ticker) Stop() {
+	t.once.Do(func() {
+		t.Ticker.Stop()
+		close(t.done)
+	})
+}
+
 // Every triggers f every t time.Duration until the end of days, or when a Stop()
 // is called on the Ticker that is returned by the Every function.
 // It does not wait for the previous execution of f to finish before
 // it fires the next f.
-func Every(t time.Duration, f func()) *time.Ticker {
-	ticker := time.NewTicker(t)
+func Every(t time.Duration, f func()) *Ticker {
+	ticker := &Ticker{
+		Ticker: time.NewTicker(t),
+		done:   make(chan bool),
+	}
 
 	go func() {
 		for {
 			select {
 			case <-ticker.C:
+				select {
+				case <-ticker.done:
+					return
+				default:
+				}
 				f()
+			case <-ticker.done:
+				return
 			}
 		}
 	}()
@@ -62,3 +91,15 @@ func ToScale(input, min, max float64) float64 {
 func DefaultName(name string) string {
 	return fmt.Sprintf("%s-%X", name, Rand(int(^uint(0)>>1)))
 }
+
+// ErrOrNil combines the errors in errs into a single error, the way Connect,
+// Start and Halt do, so that APIs returning []error can be checked with
+// `if err != nil`. It returns nil when errs holds no error.
+func ErrOrNil(errs []error) (err error) {
+	for _, e := range errs {
+		if e != nil {
+			err = multierror.Append(err, e)
+		}
+	}
+	return err
+}
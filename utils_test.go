@@ -1,10 +1,13 @@
 package gobot
 
 import (
+	"errors"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
 
+	multierror "github.com/hashicorp/go-multierror"
 	"gobot.io/x/gobot/gobottest"
 )
 
@@ -45,6 +48,17 @@ func TestEveryWhenStopped(t *testing.T) {
 	}
 }
 
+func TestEveryStopReleasesGoroutine(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		done := Every(time.Hour, func() {})
+		done.Stop()
+		done.Stop()
+	}
+
+	gobottest.AssertMaxGoroutines(t, before)
+}
+
 func TestAfter(t *testing.T) {
 	i := 0
 	sem := make(chan bool)
@@ -85,3 +99,14 @@ func TestDefaultName(t *testing.T) {
 	name := DefaultName("tester")
 	gobottest.Assert(t, strings.Contains(name, "tester"), true)
 }
+
+func TestErrOrNil(t *testing.T) {
+	gobottest.Assert(t, ErrOrNil(nil), nil)
+	gobottest.Assert(t, ErrOrNil([]error{nil, nil}), nil)
+
+	e := errors.New("failed")
+	var expected error
+	expected = multierror.Append(expected, e)
+	expected = multierror.Append(expected, e)
+	gobottest.Assert(t, ErrOrNil([]error{e, nil, e}), expected)
+}